@@ -0,0 +1,112 @@
+package linuxtool
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+// Types of the auxiliary vector entries, the AT_* constants of getauxval(3).
+const (
+	AtNull       = 0
+	AtPageSize   = 6
+	AtClockTicks = 17
+)
+
+// DefaultClockTicks is USER_HZ on all architectures Linux runs on today, used
+// when the auxiliary vector cannot be read.
+const DefaultClockTicks = 100
+
+// nativeEndian is the byte order of the auxiliary vector, the one of the
+// running kernel.
+var nativeEndian = func() binary.ByteOrder {
+
+	i := uint16(1)
+
+	if *(*byte)(unsafe.Pointer(&i)) == 1 {
+		return binary.LittleEndian
+	}
+
+	return binary.BigEndian
+}()
+
+// ReadAuxv reads the auxiliary vector the kernel passed to a process, from its
+// auxv file, as a map of entry type to value. Entries are pairs of native
+// words.
+func ReadAuxv(path string) (map[uint64]uint64, error) {
+
+	b, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	word := strconv.IntSize / 8
+
+	auxv := make(map[uint64]uint64)
+
+	for i := 0; i+2*word <= len(b); i += 2 * word {
+
+		var k, v uint64
+
+		if word == 8 {
+			k = nativeEndian.Uint64(b[i:])
+			v = nativeEndian.Uint64(b[i+word:])
+		} else {
+			k = uint64(nativeEndian.Uint32(b[i:]))
+			v = uint64(nativeEndian.Uint32(b[i+word:]))
+		}
+
+		if k == AtNull {
+			break
+		}
+
+		auxv[k] = v
+	}
+
+	return auxv, nil
+}
+
+var clockTicks struct {
+	once  sync.Once
+	ticks int64
+}
+
+// ClockTicks returns USER_HZ, as sysconf(_SC_CLK_TCK) does, the unit of the
+// times of ProcessStat and CPUStat. It is read once from /proc/self/auxv,
+// falling back to DefaultClockTicks.
+func ClockTicks() int64 {
+
+	clockTicks.once.Do(func() {
+
+		clockTicks.ticks = DefaultClockTicks
+
+		auxv, err := ReadAuxv("/proc/self/auxv")
+
+		if err == nil && auxv[AtClockTicks] > 0 {
+			clockTicks.ticks = int64(auxv[AtClockTicks])
+		}
+	})
+
+	return clockTicks.ticks
+}
+
+// PageSize returns the size of a memory page in bytes, the unit of
+// ProcessStatm and ProcessStat.Rss.
+func PageSize() int64 {
+	return int64(os.Getpagesize())
+}
+
+// TicksToDuration converts clock ticks to a duration. Whole seconds and the
+// remaining ticks are converted apart, so that ticks*time.Second does not
+// overflow for the CPU time of busy long-running processes.
+func TicksToDuration(ticks uint64) time.Duration {
+
+	hz := uint64(ClockTicks())
+
+	return time.Duration(ticks/hz)*time.Second + time.Duration(ticks%hz)*time.Second/time.Duration(hz)
+}
@@ -0,0 +1,62 @@
+package linuxtool
+
+import (
+	"encoding/binary"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestReadAuxv(t *testing.T) {
+
+	// The fixture was taken on x86_64.
+	if strconv.IntSize != 64 || nativeEndian != binary.LittleEndian {
+		t.Skip("auxv fixture is 64 bit little endian")
+	}
+
+	auxv, err := ReadAuxv("proc/3323/auxv")
+
+	if err != nil {
+		t.Fatal("auxv read fail", err)
+	}
+
+	if len(auxv) != 14 || auxv[AtPageSize] != 4096 || auxv[AtClockTicks] != 100 {
+		t.Error("unexpected auxv", auxv)
+	}
+}
+
+func TestClockTicks(t *testing.T) {
+
+	auxv, err := ReadAuxv("/proc/self/auxv")
+
+	if err != nil {
+		t.Skip("no auxv", err)
+	}
+
+	if ClockTicks() != int64(auxv[AtClockTicks]) {
+		t.Error("unexpected clock ticks", ClockTicks(), auxv[AtClockTicks])
+	}
+
+	if PageSize() != int64(auxv[AtPageSize]) {
+		t.Error("unexpected page size", PageSize(), auxv[AtPageSize])
+	}
+
+	if d := TicksToDuration(uint64(ClockTicks()) * 3 / 2); d != 1500*time.Millisecond {
+		t.Error("unexpected duration", d)
+	}
+}
+
+func TestTicksToDuration(t *testing.T) {
+
+	hz := uint64(ClockTicks())
+
+	// Ten years of CPU time and a half second, more than an int64 of
+	// nanoseconds can hold once multiplied by time.Second.
+	ticks := 10*365*24*3600*hz + hz/2
+
+	expected := 10*365*24*time.Hour + time.Second*time.Duration(hz/2)/time.Duration(hz)
+
+	if d := TicksToDuration(ticks); d != expected {
+		t.Error("unexpected duration", d, expected)
+	}
+}
@@ -0,0 +1,147 @@
+package linuxtool
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Capability is a Linux capability, by its bit number, see capabilities(7).
+// The constants are named after the kernel ones, e.g. CapSysAdmin for
+// CAP_SYS_ADMIN.
+type Capability uint
+
+const (
+	CapChown Capability = iota
+	CapDACOverride
+	CapDACReadSearch
+	CapFowner
+	CapFsetid
+	CapKill
+	CapSetgid
+	CapSetuid
+	CapSetpcap
+	CapLinuxImmutable
+	CapNetBindService
+	CapNetBroadcast
+	CapNetAdmin
+	CapNetRaw
+	CapIPCLock
+	CapIPCOwner
+	CapSysModule
+	CapSysRawIO
+	CapSysChroot
+	CapSysPtrace
+	CapSysPacct
+	CapSysAdmin
+	CapSysBoot
+	CapSysNice
+	CapSysResource
+	CapSysTime
+	CapSysTTYConfig
+	CapMknod
+	CapLease
+	CapAuditWrite
+	CapAuditControl
+	CapSetfcap
+	CapMACOverride
+	CapMACAdmin
+	CapSyslog
+	CapWakeAlarm
+	CapBlockSuspend
+	CapAuditRead
+	CapPerfmon
+	CapBPF
+	CapCheckpointRestore
+)
+
+var capabilityNames = []string{
+	"CAP_CHOWN", "CAP_DAC_OVERRIDE", "CAP_DAC_READ_SEARCH", "CAP_FOWNER",
+	"CAP_FSETID", "CAP_KILL", "CAP_SETGID", "CAP_SETUID", "CAP_SETPCAP",
+	"CAP_LINUX_IMMUTABLE", "CAP_NET_BIND_SERVICE", "CAP_NET_BROADCAST",
+	"CAP_NET_ADMIN", "CAP_NET_RAW", "CAP_IPC_LOCK", "CAP_IPC_OWNER",
+	"CAP_SYS_MODULE", "CAP_SYS_RAWIO", "CAP_SYS_CHROOT", "CAP_SYS_PTRACE",
+	"CAP_SYS_PACCT", "CAP_SYS_ADMIN", "CAP_SYS_BOOT", "CAP_SYS_NICE",
+	"CAP_SYS_RESOURCE", "CAP_SYS_TIME", "CAP_SYS_TTY_CONFIG", "CAP_MKNOD",
+	"CAP_LEASE", "CAP_AUDIT_WRITE", "CAP_AUDIT_CONTROL", "CAP_SETFCAP",
+	"CAP_MAC_OVERRIDE", "CAP_MAC_ADMIN", "CAP_SYSLOG", "CAP_WAKE_ALARM",
+	"CAP_BLOCK_SUSPEND", "CAP_AUDIT_READ", "CAP_PERFMON", "CAP_BPF",
+	"CAP_CHECKPOINT_RESTORE",
+}
+
+// String returns the name of the capability, e.g. "CAP_SYS_ADMIN", or
+// "CAP_<n>" for capabilities newer than this package.
+func (c Capability) String() string {
+
+	if int(c) < len(capabilityNames) {
+		return capabilityNames[c]
+	}
+
+	return "CAP_" + strconv.FormatUint(uint64(c), 10)
+}
+
+// CapabilitySet is a capability bitmask, as in the Cap* lines of
+// /proc/<pid>/status.
+type CapabilitySet uint64
+
+// DangerousCapabilities are the capabilities which amount to root, or let a
+// process escape a container.
+var DangerousCapabilities = NewCapabilitySet(
+	CapDACOverride, CapDACReadSearch, CapSetuid, CapSetgid,
+	CapNetAdmin, CapNetRaw, CapSysModule, CapSysRawIO,
+	CapSysPtrace, CapSysAdmin, CapSysBoot, CapMknod, CapBPF,
+)
+
+// NewCapabilitySet returns the set of caps.
+func NewCapabilitySet(caps ...Capability) CapabilitySet {
+
+	var s CapabilitySet
+
+	for _, c := range caps {
+		s |= 1 << c
+	}
+
+	return s
+}
+
+// Has reports whether c is in the set.
+func (s CapabilitySet) Has(c Capability) bool {
+	return c < 64 && s&(1<<c) != 0
+}
+
+// Intersect returns the capabilities in both s and other, e.g.
+// CapEff.Intersect(DangerousCapabilities).
+func (s CapabilitySet) Intersect(other CapabilitySet) CapabilitySet {
+	return s & other
+}
+
+// Capabilities returns the capabilities in the set, in bit order.
+func (s CapabilitySet) Capabilities() []Capability {
+
+	var caps []Capability
+
+	for c := Capability(0); c < 64; c++ {
+		if s.Has(c) {
+			caps = append(caps, c)
+		}
+	}
+
+	return caps
+}
+
+// List returns the names of the capabilities in the set, in bit order.
+func (s CapabilitySet) List() []string {
+
+	caps := s.Capabilities()
+	names := make([]string, len(caps))
+
+	for i, c := range caps {
+		names[i] = c.String()
+	}
+
+	return names
+}
+
+// String returns the names of the capabilities in the set, comma separated.
+func (s CapabilitySet) String() string {
+	return strings.Join(s.List(), ",")
+}
@@ -0,0 +1,45 @@
+package linuxtool
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCapabilitySet(t *testing.T) {
+
+	status, err := ReadProcessStatus("proc/4854/task/4854/status")
+
+	if err != nil {
+		t.Fatal("process status read fail", err)
+	}
+
+	if status.CapEff != 0 || status.CapEff.String() != "" || len(status.CapEff.List()) != 0 {
+		t.Error("unexpected effective capabilities", status.CapEff)
+	}
+
+	bnd := status.CapBnd.List()
+
+	if len(bnd) != 41 || bnd[0] != "CAP_CHOWN" || bnd[40] != "CAP_CHECKPOINT_RESTORE" {
+		t.Error("unexpected bounding capabilities", bnd)
+	}
+
+	s := NewCapabilitySet(CapNetAdmin, CapNetBindService, CapSysAdmin)
+
+	if !s.Has(CapNetAdmin) || s.Has(CapNetRaw) || s.Has(Capability(64)) {
+		t.Error("unexpected membership", s)
+	}
+
+	if s.String() != "CAP_NET_BIND_SERVICE,CAP_NET_ADMIN,CAP_SYS_ADMIN" {
+		t.Error("unexpected string", s.String())
+	}
+
+	expected := []Capability{CapNetAdmin, CapSysAdmin}
+
+	if caps := s.Intersect(DangerousCapabilities).Capabilities(); !reflect.DeepEqual(caps, expected) {
+		t.Error("unexpected dangerous capabilities", caps)
+	}
+
+	if Capability(45).String() != "CAP_45" {
+		t.Error("unexpected name of unknown capability", Capability(45))
+	}
+}
@@ -0,0 +1,120 @@
+package linuxtool
+
+// CPUUsage is the share of CPU time, in percent, spent in each state between
+// two /proc/stat samples, as reported by mpstat.
+//
+// The kernel already accounts guest time in user time (and guest nice time in
+// nice time), so User and Nice exclude it and the states add up to 100.
+type CPUUsage struct {
+	Id        string  `json:"id"`
+	User      float64 `json:"user"`
+	Nice      float64 `json:"nice"`
+	System    float64 `json:"system"`
+	Idle      float64 `json:"idle"`
+	IOWait    float64 `json:"iowait"`
+	IRQ       float64 `json:"irq"`
+	SoftIRQ   float64 `json:"softirq"`
+	Steal     float64 `json:"steal"`
+	Guest     float64 `json:"guest"`
+	GuestNice float64 `json:"guest_nice"`
+	Offline   bool    `json:"offline"` // missing from one of the samples, all values are 0
+}
+
+type CPUUtilization struct {
+	CPUUsageAll CPUUsage   `json:"cpu_all"`
+	CPUUsages   []CPUUsage `json:"cpus"`
+}
+
+// CalculateCPUUtilization returns the CPU utilization between the prev and
+// curr samples of /proc/stat.
+//
+// CPUs which went offline or came online between the samples can't be
+// measured and are reported as Offline. Counters going backwards, such as
+// iowait on some kernels, are treated as not having moved.
+func CalculateCPUUtilization(prev, curr *Stat) *CPUUtilization {
+
+	u := &CPUUtilization{
+		CPUUsageAll: calculateCPUUsage(&prev.CPUStatAll, &curr.CPUStatAll),
+		CPUUsages:   make([]CPUUsage, 0, len(curr.CPUStats)),
+	}
+
+	before := make(map[string]*CPUStat, len(prev.CPUStats))
+
+	for i := range prev.CPUStats {
+		before[prev.CPUStats[i].Id] = &prev.CPUStats[i]
+	}
+
+	for i := range curr.CPUStats {
+
+		c := &curr.CPUStats[i]
+
+		if p, ok := before[c.Id]; ok {
+			u.CPUUsages = append(u.CPUUsages, calculateCPUUsage(p, c))
+			delete(before, c.Id)
+		} else {
+			u.CPUUsages = append(u.CPUUsages, CPUUsage{Id: c.Id, Offline: true})
+		}
+	}
+
+	for i := range prev.CPUStats {
+		if p, ok := before[prev.CPUStats[i].Id]; ok {
+			u.CPUUsages = append(u.CPUUsages, CPUUsage{Id: p.Id, Offline: true})
+		}
+	}
+
+	return u
+}
+
+func calculateCPUUsage(prev, curr *CPUStat) CPUUsage {
+
+	user := tickDelta(satSub(prev.User, prev.Guest), satSub(curr.User, curr.Guest))
+	nice := tickDelta(satSub(prev.Nice, prev.GuestNice), satSub(curr.Nice, curr.GuestNice))
+	system := tickDelta(prev.System, curr.System)
+	idle := tickDelta(prev.Idle, curr.Idle)
+	iowait := tickDelta(prev.IOWait, curr.IOWait)
+	irq := tickDelta(prev.IRQ, curr.IRQ)
+	softirq := tickDelta(prev.SoftIRQ, curr.SoftIRQ)
+	steal := tickDelta(prev.Steal, curr.Steal)
+	guest := tickDelta(prev.Guest, curr.Guest)
+	guestNice := tickDelta(prev.GuestNice, curr.GuestNice)
+
+	total := user + nice + system + idle + iowait + irq + softirq + steal + guest + guestNice
+
+	usage := CPUUsage{Id: curr.Id}
+
+	// No tick elapsed, e.g. a tickless idle CPU.
+	if total == 0 {
+		usage.Idle = 100
+		return usage
+	}
+
+	percent := func(n uint64) float64 {
+		return float64(n) * 100 / float64(total)
+	}
+
+	usage.User = percent(user)
+	usage.Nice = percent(nice)
+	usage.System = percent(system)
+	usage.Idle = percent(idle)
+	usage.IOWait = percent(iowait)
+	usage.IRQ = percent(irq)
+	usage.SoftIRQ = percent(softirq)
+	usage.Steal = percent(steal)
+	usage.Guest = percent(guest)
+	usage.GuestNice = percent(guestNice)
+
+	return usage
+}
+
+// tickDelta returns curr - prev, or 0 if the counter went backwards.
+func tickDelta(prev, curr uint64) uint64 {
+	return satSub(curr, prev)
+}
+
+// satSub returns a - b, or 0 if b is greater than a.
+func satSub(a, b uint64) uint64 {
+	if b > a {
+		return 0
+	}
+	return a - b
+}
@@ -0,0 +1,52 @@
+package linuxtool
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCalculateCPUUtilization(t *testing.T) {
+
+	prev := &Stat{
+		CPUStatAll: CPUStat{Id: "cpu", User: 1000, Nice: 100, System: 500, Idle: 8000, IOWait: 300, Guest: 200},
+		CPUStats: []CPUStat{
+			{Id: "cpu0", User: 600, Nice: 100, System: 300, Idle: 4000, IOWait: 200, Guest: 200},
+			{Id: "cpu1", User: 400, System: 200, Idle: 4000, IOWait: 100},
+		},
+	}
+
+	curr := &Stat{
+		CPUStatAll: CPUStat{Id: "cpu", User: 1300, Nice: 100, System: 600, Idle: 8500, IOWait: 290, IRQ: 50, SoftIRQ: 50, Guest: 300},
+		CPUStats: []CPUStat{
+			{Id: "cpu0", User: 900, Nice: 100, System: 400, Idle: 4500, IOWait: 190, IRQ: 50, SoftIRQ: 50, Guest: 300},
+			{Id: "cpu2", User: 10, Idle: 10},
+		},
+	}
+
+	u := CalculateCPUUtilization(prev, curr)
+
+	// deltas: user 300 (200 of it without guest), system 100, idle 500,
+	// iowait went backwards, irq 50, softirq 50, guest 100: 1000 ticks in total
+	cpu0 := CPUUsage{Id: "cpu0", User: 20, System: 10, Idle: 50, IRQ: 5, SoftIRQ: 5, Guest: 10}
+
+	expected := &CPUUtilization{
+		CPUUsageAll: CPUUsage{Id: "cpu", User: 20, System: 10, Idle: 50, IRQ: 5, SoftIRQ: 5, Guest: 10},
+		CPUUsages: []CPUUsage{
+			cpu0,
+			{Id: "cpu2", Offline: true},
+			{Id: "cpu1", Offline: true},
+		},
+	}
+
+	if !reflect.DeepEqual(u, expected) {
+		t.Errorf("not equal to expected %+v", expected)
+	}
+
+	t.Logf("%+v", u)
+
+	idle := CalculateCPUUtilization(curr, curr)
+
+	if idle.CPUUsageAll.Idle != 100 {
+		t.Error("expected an idle cpu when no tick elapsed")
+	}
+}
@@ -68,11 +68,17 @@ func (info *CPUInfo) NumPhysicalCPU() int {
 }
 
 func ReadCPUInfo(path string) (*CPUInfo, error) {
+	return readCPUInfo(path, nil)
+}
+
+func readCPUInfo(path string, onError ParseErrorHandler) (*CPUInfo, error) {
 	b, err := ioutil.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
 
+	p := newParser(path, onError)
+
 	content := string(b)
 	lines := strings.Split(content, "\n")
 
@@ -93,33 +99,47 @@ func ReadCPUInfo(path string) (*CPUInfo, error) {
 		}
 
 		subMatches := cpuInfoRegExp.FindStringSubmatch(line)
+		if subMatches == nil {
+			if err = p.fail(i+1, "", line, ErrTooFewFields); err != nil {
+				return nil, err
+			}
+			continue
+		}
 		key = subMatches[1]
 		value = subMatches[2]
 
 		switch key {
 		case "processor":
-			processor.Id = ParseInt64(value)
+			processor.Id, err = ParseInt(value)
 		case "vendor_id":
 			processor.VendorId = value
 		case "model":
+			// not a number on every architecture, e.g. ppc64
 			processor.Model = ParseInt64(value)
 		case "model name":
 			processor.ModelName = value
 		case "flags":
 			processor.Flags = strings.Fields(value)
 		case "cpu cores":
-			processor.Cores = ParseInt64(value)
+			processor.Cores, err = ParseInt(value)
 		case "cpu MHz":
-			processor.MHz = ParseFloat64(value)
+			processor.MHz, err = ParseFloat(value)
 		case "cache size":
-			processor.CacheSize, _ = strconv.ParseInt(value[:strings.IndexAny(value, " \t\n")], 10, 64)
-			if strings.HasSuffix(line, "MB") {
+			if f := strings.Fields(value); len(f) == 0 {
+				err = ErrTooFewFields
+			} else if processor.CacheSize, err = ParseInt(f[0]); err == nil && strings.HasSuffix(line, "MB") {
 				processor.CacheSize *= 1024
 			}
 		case "physical id":
-			processor.PhysicalId = ParseInt64(value)
+			processor.PhysicalId, err = ParseInt(value)
 		case "core id":
-			processor.CoreId = ParseInt64(value)
+			processor.CoreId, err = ParseInt(value)
+		}
+
+		if err != nil {
+			if err = p.fail(i+1, key, line, err); err != nil {
+				return nil, err
+			}
 		}
 		/*
 			processor	: 0
@@ -0,0 +1,271 @@
+package linuxtool
+
+import (
+	"errors"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// DefaultStatfsTimeout is the time the statfs calls of a usage report may
+// take before the filesystems which did not answer are reported with
+// ErrStatfsTimeout.
+const DefaultStatfsTimeout = 5 * time.Second
+
+// ErrStatfsTimeout is set as the FSUsage error of a filesystem which did not
+// answer statfs in time, typically a hung NFS mount, or whose statfs from an
+// earlier report has not returned yet.
+var ErrStatfsTimeout = errors.New("statfs timeout")
+
+// FSUsage is the usage of a mounted filesystem, a line of df.
+type FSUsage struct {
+	Major       int     `json:"major"`
+	Minor       int     `json:"minor"`
+	Source      string  `json:"source"`
+	MountPoint  string  `json:"mountpoint"`
+	FSType      string  `json:"fstype"`
+	Size        uint64  `json:"size"`      // bytes
+	Used        uint64  `json:"used"`      // bytes
+	Available   uint64  `json:"available"` // bytes available to non-root users
+	UsePercent  float64 `json:"use_percent"`
+	InodesTotal uint64  `json:"inodes_total"`
+	InodesUsed  uint64  `json:"inodes_used"`
+	InodesFree  uint64  `json:"inodes_free"`
+	ReadOnly    bool    `json:"readonly"`
+	Err         error   `json:"-"` // statfs error, the usage fields are 0
+}
+
+// FSUsageOptions selects the filesystems of a usage report.
+type FSUsageOptions struct {
+	IncludeTmpfs bool          // include tmpfs, devtmpfs and ramfs
+	Timeout      time.Duration // for all the statfs calls, DefaultStatfsTimeout if 0
+}
+
+// pseudoFSTypes are the filesystems without disk space which are never
+// reported, tmpfsTypes those reported on demand.
+var (
+	pseudoFSTypes = map[string]bool{
+		"autofs": true, "binfmt_misc": true, "bpf": true, "cgroup": true,
+		"cgroup2": true, "configfs": true, "debugfs": true, "devpts": true,
+		"efivarfs": true, "fusectl": true, "hugetlbfs": true, "mqueue": true,
+		"nsfs": true, "proc": true, "pstore": true, "rpc_pipefs": true,
+		"securityfs": true, "selinuxfs": true, "sysfs": true, "tracefs": true,
+	}
+	tmpfsTypes = map[string]bool{
+		"tmpfs": true, "devtmpfs": true, "ramfs": true,
+	}
+)
+
+// ReadFSUsage reports the usage of every real filesystem of the mountinfo file
+// at path, like df.
+//
+// Note:
+// * A filesystem mounted several times, e.g. by bind mounts, is reported once,
+//   preferring a mount of its root.
+// * Filesystems with no blocks are left out, like df does without -a.
+// * The filesystems are queried concurrently, so the report takes at most
+//   opts.Timeout however many mounts hang. A filesystem whose statfs fails or
+//   times out is reported with Err set.
+// * The statfs of a hung mount keeps a goroutine blocked until it returns.
+//   Later reports do not query that mount again until then, so each hung
+//   mount blocks at most one goroutine.
+func ReadFSUsage(path string, opts FSUsageOptions) ([]FSUsage, error) {
+	return readFSUsage(path, opts, nil)
+}
+
+func readFSUsage(path string, opts FSUsageOptions, onError ParseErrorHandler) ([]FSUsage, error) {
+
+	mounts, err := readMountInfo(path, onError)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return calculateFSUsage(mounts, opts, syscall.Statfs), nil
+}
+
+func calculateFSUsage(mounts []MountInfo, opts FSUsageOptions, statfs func(string, *syscall.Statfs_t) error) []FSUsage {
+
+	timeout := opts.Timeout
+
+	if timeout <= 0 {
+		timeout = DefaultStatfsTimeout
+	}
+
+	type device struct {
+		major int
+		minor int
+	}
+
+	seen := make(map[device]int)
+	selected := make([]*MountInfo, 0, len(mounts))
+
+	for i := range mounts {
+
+		m := &mounts[i]
+
+		if pseudoFSTypes[m.FSType] || (tmpfsTypes[m.FSType] && !opts.IncludeTmpfs) {
+			continue
+		}
+
+		dev := device{m.Major, m.Minor}
+
+		if j, ok := seen[dev]; ok {
+			if selected[j].Root != "/" && m.Root == "/" {
+				selected[j] = m
+			}
+			continue
+		}
+
+		seen[dev] = len(selected)
+		selected = append(selected, m)
+	}
+
+	paths := make([]string, len(selected))
+
+	for i, m := range selected {
+		paths[i] = m.MountPoint
+	}
+
+	stats, errs := statfsAll(statfs, paths, timeout)
+
+	results := make([]FSUsage, 0, len(selected))
+
+	for i, m := range selected {
+
+		u := FSUsage{
+			Major:      m.Major,
+			Minor:      m.Minor,
+			Source:     m.Source,
+			MountPoint: m.MountPoint,
+			FSType:     m.FSType,
+			ReadOnly:   hasMountOption(m, "ro"),
+		}
+
+		if errs[i] != nil {
+			u.Err = errs[i]
+			results = append(results, u)
+			continue
+		}
+
+		st := stats[i]
+
+		if st.Blocks == 0 {
+			continue
+		}
+
+		d := newDisk(st)
+
+		u.Size = d.All
+		u.Used = d.Used
+		u.Available = d.Available
+		u.UsePercent = d.UsedPercent()
+		u.InodesTotal = d.InodesTotal
+		u.InodesFree = d.FreeInodes
+		u.InodesUsed = d.InodesUsed
+		u.ReadOnly = u.ReadOnly || d.ReadOnly
+
+		results = append(results, u)
+	}
+
+	return results
+}
+
+func hasMountOption(m *MountInfo, option string) bool {
+	_, mount := m.Options[option]
+	_, super := m.SuperOptions[option]
+	return mount || super
+}
+
+// pendingStatfs counts the statfs calls in flight by path, including the ones
+// of earlier reports which timed out.
+var pendingStatfs = struct {
+	sync.Mutex
+	paths map[string]int
+}{paths: make(map[string]int)}
+
+// statfsAll calls statfs on all paths concurrently and waits for them until
+// timeout, the calls still running then failing with ErrStatfsTimeout. Paths
+// whose statfs of an earlier call is still running are not called again.
+func statfsAll(statfs func(string, *syscall.Statfs_t) error, paths []string, timeout time.Duration) ([]*syscall.Statfs_t, []error) {
+
+	stats := make([]*syscall.Statfs_t, len(paths))
+	errs := make([]error, len(paths))
+
+	type result struct {
+		i   int
+		st  syscall.Statfs_t
+		err error
+	}
+
+	// Buffered, so the goroutines of timed out calls can still finish.
+	done := make(chan result, len(paths))
+
+	started := make([]bool, len(paths))
+
+	pendingStatfs.Lock()
+
+	// Checked before any call of this pass is counted, so that a path
+	// listed twice is not mistaken for a hung one.
+	for i, path := range paths {
+		started[i] = pendingStatfs.paths[path] == 0
+	}
+
+	for i, path := range paths {
+		if started[i] {
+			pendingStatfs.paths[path]++
+		} else {
+			errs[i] = ErrStatfsTimeout
+		}
+	}
+
+	pendingStatfs.Unlock()
+
+	running := 0
+
+	for i, path := range paths {
+
+		if !started[i] {
+			continue
+		}
+
+		running++
+
+		go func(i int, path string) {
+
+			r := result{i: i}
+			r.err = statfs(path, &r.st)
+
+			pendingStatfs.Lock()
+			if pendingStatfs.paths[path]--; pendingStatfs.paths[path] == 0 {
+				delete(pendingStatfs.paths, path)
+			}
+			pendingStatfs.Unlock()
+
+			done <- r
+		}(i, path)
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for ; running > 0; running-- {
+		select {
+		case r := <-done:
+			if r.err != nil {
+				errs[r.i] = r.err
+			} else {
+				stats[r.i] = &r.st
+			}
+		case <-timer.C:
+			for i := range paths {
+				if stats[i] == nil && errs[i] == nil {
+					errs[i] = ErrStatfsTimeout
+				}
+			}
+			return stats, errs
+		}
+	}
+
+	return stats, errs
+}
@@ -0,0 +1,150 @@
+package linuxtool
+
+import (
+	"strconv"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestFSUsage(t *testing.T) {
+
+	mounts, err := ReadMountInfo("proc/mountinfo")
+
+	if err != nil {
+		t.Fatal("mountinfo read fail", err)
+	}
+
+	// A bind mount of the root filesystem, listed first.
+	mounts = append([]MountInfo{{MountID: 20, Major: 8, Minor: 1, Root: "/var/lib", MountPoint: "/srv/lib", FSType: "ext4"}}, mounts...)
+
+	hang := make(chan struct{})
+	defer close(hang)
+
+	statfs := func(path string, st *syscall.Statfs_t) error {
+		switch path {
+		case "/":
+			st.Bsize, st.Frsize = 4096, 4096
+			st.Blocks, st.Bfree, st.Bavail = 1000, 300, 250
+			st.Files, st.Ffree = 100, 40
+		case "/mnt/backup disk":
+			st.Bsize = 1024
+			st.Blocks, st.Bfree, st.Bavail = 3, 2, 2
+			st.Flags = StReadOnly
+		case "/home":
+			return syscall.EACCES
+		case "/srv/tab\tdir\\x":
+			<-hang
+		}
+		return nil
+	}
+
+	usages := calculateFSUsage(mounts, FSUsageOptions{Timeout: 10 * time.Millisecond}, statfs)
+
+	var mountPoints []string
+
+	for _, u := range usages {
+		mountPoints = append(mountPoints, u.MountPoint)
+	}
+
+	// /media/usb has no blocks, /sys, /proc and the tmpfs are pseudo filesystems.
+	expected := []string{"/", "/mnt/backup disk", "/home", "/srv/tab\tdir\\x"}
+
+	if len(usages) != len(expected) {
+		t.Fatal("unexpected filesystems", mountPoints)
+	}
+
+	for i := range expected {
+		if mountPoints[i] != expected[i] {
+			t.Fatal("unexpected filesystems", mountPoints)
+		}
+	}
+
+	root := usages[0]
+
+	if root.Size != 4096000 || root.Used != 2867200 || root.Available != 1024000 ||
+		root.UsePercent != 74 || root.InodesUsed != 60 || root.ReadOnly {
+		t.Errorf("unexpected usage %+v", root)
+	}
+
+	if u := usages[1]; u.Used != 1024 || u.UsePercent != 34 || !u.ReadOnly {
+		t.Errorf("unexpected usage %+v", u)
+	}
+
+	if usages[2].Err != syscall.EACCES {
+		t.Error("unexpected error", usages[2].Err)
+	}
+
+	if usages[3].Err != ErrStatfsTimeout {
+		t.Error("unexpected error", usages[3].Err)
+	}
+
+	usages = calculateFSUsage(mounts, FSUsageOptions{IncludeTmpfs: true, Timeout: time.Millisecond}, statfs)
+
+	if len(usages) != 4 {
+		t.Error("tmpfs without blocks should be left out", len(usages))
+	}
+}
+
+func TestFSUsageHungMounts(t *testing.T) {
+
+	var mounts []MountInfo
+
+	for i := 0; i < 5; i++ {
+		mounts = append(mounts, MountInfo{Major: 0, Minor: 50 + i, Root: "/", MountPoint: "/nfs/hung" + strconv.Itoa(i), FSType: "nfs4"})
+	}
+
+	hang := make(chan struct{})
+	defer close(hang)
+
+	var calls int32
+
+	statfs := func(path string, st *syscall.Statfs_t) error {
+		atomic.AddInt32(&calls, 1)
+		<-hang
+		return nil
+	}
+
+	timeout := 50 * time.Millisecond
+	start := time.Now()
+
+	usages := calculateFSUsage(mounts, FSUsageOptions{Timeout: timeout}, statfs)
+
+	// The mounts are queried concurrently, not one timeout after another.
+	if elapsed := time.Since(start); elapsed >= 3*timeout {
+		t.Error("hung mounts blocked the report for", elapsed)
+	}
+
+	if len(usages) != len(mounts) {
+		t.Fatal("unexpected filesystems", usages)
+	}
+
+	for _, u := range usages {
+		if u.Err != ErrStatfsTimeout {
+			t.Error("unexpected error", u.MountPoint, u.Err)
+		}
+	}
+
+	// The mounts still hung are not queried again.
+	usages = calculateFSUsage(mounts, FSUsageOptions{Timeout: timeout}, statfs)
+
+	if n := atomic.LoadInt32(&calls); n != int32(len(mounts)) {
+		t.Error("hung mounts queried again", n)
+	}
+
+	if len(usages) != len(mounts) || usages[0].Err != ErrStatfsTimeout {
+		t.Error("unexpected usages", usages)
+	}
+}
+
+func TestReadFSUsage(t *testing.T) {
+
+	usages, err := ReadFSUsage("/proc/self/mountinfo", FSUsageOptions{})
+
+	if err != nil {
+		t.Fatal("fs usage read fail", err)
+	}
+
+	t.Logf("%+v", usages)
+}
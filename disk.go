@@ -4,11 +4,35 @@ import (
 	"syscall"
 )
 
+// Mount flags of statvfs(3), the ST_* constants, found in Disk.Flags.
+const (
+	StReadOnly    = 0x1
+	StNoSuid      = 0x2
+	StNoDev       = 0x4
+	StNoExec      = 0x8
+	StSynchronous = 0x10
+	StMandLock    = 0x40
+	StNoAtime     = 0x400
+	StNoDirAtime  = 0x800
+	StRelAtime    = 0x1000
+)
+
+// Disk is the space and inode usage of a filesystem, from statfs(2).
+//
+// Free includes the blocks reserved for root, Available does not and is what
+// df reports as available.
 type Disk struct {
-	All        uint64 `json:"all"`
-	Used       uint64 `json:"used"`
-	Free       uint64 `json:"free"`
-	FreeInodes uint64 `json:"freeInodes"`
+	All          uint64 `json:"all"`
+	Used         uint64 `json:"used"`
+	Free         uint64 `json:"free"`
+	Available    uint64 `json:"available"`
+	FreeInodes   uint64 `json:"freeInodes"`
+	InodesTotal  uint64 `json:"inodesTotal"`
+	InodesUsed   uint64 `json:"inodesUsed"`
+	FragmentSize uint64 `json:"fragmentSize"`
+	FSType       int64  `json:"fsType"` // magic number, e.g. 0xEF53 for ext4
+	Flags        int64  `json:"flags"`  // St* mount flags
+	ReadOnly     bool   `json:"readOnly"`
 }
 
 func ReadDisk(path string) (*Disk, error) {
@@ -17,10 +41,62 @@ func ReadDisk(path string) (*Disk, error) {
 	if err != nil {
 		return nil, err
 	}
+	return newDisk(&fs), nil
+}
+
+func newDisk(fs *syscall.Statfs_t) *Disk {
+	// Block counts are in fragments, Bsize is only the preferred I/O size.
+	frsize := uint64(fs.Frsize)
+	if frsize == 0 {
+		frsize = uint64(fs.Bsize)
+	}
 	disk := Disk{}
-	disk.All = fs.Blocks * uint64(fs.Bsize)
-	disk.Free = fs.Bfree * uint64(fs.Bsize)
-	disk.Used = disk.All - disk.Free
+	disk.All = fs.Blocks * frsize
+	disk.Free = fs.Bfree * frsize
+	disk.Used = satSub(disk.All, disk.Free)
+	disk.Available = fs.Bavail * frsize
 	disk.FreeInodes = fs.Ffree
-	return &disk, nil
+	disk.InodesTotal = fs.Files
+	disk.InodesUsed = satSub(fs.Files, fs.Ffree)
+	disk.FragmentSize = frsize
+	disk.FSType = int64(fs.Type)
+	disk.Flags = int64(fs.Flags)
+	disk.ReadOnly = disk.Flags&StReadOnly != 0
+	return &disk
+}
+
+// UsedPercent returns the used space in percent of the space usable by
+// non-root users, rounded up, as the Use% column of df.
+func (d *Disk) UsedPercent() float64 {
+	return dfPercent(d.Used, d.Available)
+}
+
+// InodesUsedPercent returns the used inodes in percent, rounded up, as the
+// IUse% column of df -i. Filesystems without inodes report 0.
+func (d *Disk) InodesUsedPercent() float64 {
+	return dfPercent(d.InodesUsed, d.FreeInodes)
+}
+
+// HasFlag reports whether the St* mount flag is set.
+func (d *Disk) HasFlag(flag int64) bool {
+	return d.Flags&flag != 0
+}
+
+// dfPercent returns used / (used + available) in percent, rounded up like df
+// does. Blocks reserved for root are not counted as available.
+func dfPercent(used, available uint64) float64 {
+
+	total := used + available
+
+	if total == 0 {
+		return 0
+	}
+
+	percent := used * 100 / total
+
+	if used*100%total != 0 {
+		percent++
+	}
+
+	return float64(percent)
 }
@@ -0,0 +1,184 @@
+package linuxtool
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type DiskType string
+
+const (
+	DiskTypeDisk      DiskType = "disk"      // whole disk backed by hardware
+	DiskTypePartition DiskType = "partition" // partition of a disk, see DiskDevice.Parent
+	DiskTypeLoop      DiskType = "loop"      // loop device
+	DiskTypeDM        DiskType = "dm"        // device mapper, e.g. LVM or dm-crypt
+	DiskTypeMD        DiskType = "md"        // software RAID
+	DiskTypeZRAM      DiskType = "zram"      // compressed RAM disk
+	DiskTypeRAM       DiskType = "ram"       // RAM disk
+	DiskTypeNBD       DiskType = "nbd"       // network block device
+	DiskTypeROM       DiskType = "rom"       // optical drive
+	DiskTypeOther     DiskType = "other"     // any other virtual device, or not found in sysfs
+)
+
+// DiskDevice is a DiskStat enriched with the block device information found
+// in /sys/block and /sys/class/block.
+type DiskDevice struct {
+	DiskStat
+	Type       DiskType `json:"type"`
+	Parent     string   `json:"parent"`     // disk holding the partition, empty for other types
+	DMName     string   `json:"dm_name"`    // device mapper name (dm/name), e.g. vg0-root
+	Rotational bool     `json:"rotational"` // spinning disk, from queue/rotational
+}
+
+// ReadDiskDevices classifies the stats read from /proc/diskstats using the
+// sysfs mounted at sysPath. A device is only classified as a disk if sysfs
+// places it under a hardware device; devices missing from sysfs, for example
+// removed since stats was read, are classified by name only, as
+// DiskTypeOther if their name is not known.
+func ReadDiskDevices(sysPath string, stats []DiskStat) ([]DiskDevice, error) {
+
+	devices := make([]DiskDevice, len(stats))
+
+	for i := range stats {
+
+		devices[i].DiskStat = stats[i]
+
+		if err := readDiskDevice(sysPath, &devices[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return devices, nil
+}
+
+// PhysicalDisks returns the whole disks of devices, leaving out partitions,
+// whose I/O is already accounted in their disk, and virtual devices.
+func PhysicalDisks(devices []DiskDevice) []DiskDevice {
+
+	disks := make([]DiskDevice, 0, len(devices))
+
+	for _, d := range devices {
+		if d.Type == DiskTypeDisk {
+			disks = append(disks, d)
+		}
+	}
+
+	return disks
+}
+
+func readDiskDevice(sysPath string, d *DiskDevice) error {
+
+	// Slashes in device names, e.g. cciss/c0d0, are replaced by '!' in sysfs.
+	name := strings.Replace(d.Name, "/", "!", -1)
+	dir := filepath.Join(sysPath, "class", "block", name)
+
+	d.Type = diskTypeFromName(d.Name)
+
+	if b, err := ioutil.ReadFile(filepath.Join(dir, "dm", "name")); err == nil {
+		d.Type = DiskTypeDM
+		d.DMName = strings.TrimSpace(string(b))
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	parent, err := partitionParent(sysPath, name)
+
+	if err != nil {
+		return err
+	}
+
+	if d.Type == DiskTypeOther && parent == "" {
+
+		hardware, err := isHardwareBlockDevice(dir)
+
+		if err != nil {
+			return err
+		}
+
+		if hardware {
+			d.Type = DiskTypeDisk
+		}
+	}
+
+	if parent != "" {
+		d.Type = DiskTypePartition
+		d.Parent = strings.Replace(parent, "!", "/", -1)
+		dir = filepath.Join(sysPath, "class", "block", parent)
+	}
+
+	// Partitions have no queue, they share the one of their disk.
+	if b, err := ioutil.ReadFile(filepath.Join(dir, "queue", "rotational")); err == nil {
+		d.Rotational = strings.TrimSpace(string(b)) == "1"
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+// partitionParent returns the sysfs name of the disk holding the partition
+// name, or "" if name is not a partition.
+func partitionParent(sysPath string, name string) (string, error) {
+
+	dir := filepath.Join(sysPath, "class", "block", name)
+
+	if _, err := os.Stat(filepath.Join(dir, "partition")); err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	// class/block/sda1 links to .../block/sda/sda1
+	target, err := filepath.EvalSymlinks(dir)
+
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Base(filepath.Dir(target)), nil
+}
+
+// isHardwareBlockDevice reports whether the sysfs directory of a block device
+// is found under the device of a bus, e.g. a SATA controller, rather than
+// under devices/virtual. Devices missing from sysfs are not.
+func isHardwareBlockDevice(dir string) (bool, error) {
+
+	target, err := filepath.EvalSymlinks(dir)
+
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return !strings.Contains(filepath.ToSlash(target), "/devices/virtual/"), nil
+}
+
+// diskTypeFromName classifies the devices whose type the kernel name tells,
+// and returns DiskTypeOther for the others.
+func diskTypeFromName(name string) DiskType {
+
+	switch {
+	case strings.HasPrefix(name, "loop"):
+		return DiskTypeLoop
+	case strings.HasPrefix(name, "dm-"):
+		return DiskTypeDM
+	case strings.HasPrefix(name, "md"):
+		return DiskTypeMD
+	case strings.HasPrefix(name, "zram"):
+		return DiskTypeZRAM
+	case strings.HasPrefix(name, "ram"):
+		return DiskTypeRAM
+	case strings.HasPrefix(name, "nbd"):
+		return DiskTypeNBD
+	case strings.HasPrefix(name, "sr"):
+		return DiskTypeROM
+	}
+
+	return DiskTypeOther
+}
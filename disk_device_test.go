@@ -0,0 +1,76 @@
+package linuxtool
+
+import (
+	"testing"
+)
+
+func TestDiskDevices(t *testing.T) {
+
+	stats := []DiskStat{
+		{Major: 8, Minor: 0, Name: "sda"},
+		{Major: 8, Minor: 1, Name: "sda1"},
+		{Major: 8, Minor: 16, Name: "sdb"},
+		{Major: 8, Minor: 17, Name: "sdb1"},
+		{Major: 7, Minor: 0, Name: "loop0"},
+		{Major: 9, Minor: 2, Name: "md2"},
+		{Major: 253, Minor: 0, Name: "dm-0"},
+		{Major: 252, Minor: 0, Name: "zram0"},
+		{Major: 1, Minor: 0, Name: "ram0"}, // missing from sysfs
+		{Major: 11, Minor: 0, Name: "sr0"},
+		{Major: 251, Minor: 0, Name: "rbd0"},
+		{Major: 43, Minor: 0, Name: "nbd0"}, // missing from sysfs
+		{Major: 8, Minor: 32, Name: "sdc"},  // missing from sysfs
+	}
+
+	devices, err := NewSysFS("sys").DiskDevices(stats)
+
+	if err != nil {
+		t.Fatal("disk devices read fail", err)
+	}
+
+	type expectation struct {
+		Type       DiskType
+		Parent     string
+		DMName     string
+		Rotational bool
+	}
+
+	expected := []expectation{
+		{DiskTypeDisk, "", "", true},
+		{DiskTypePartition, "sda", "", true},
+		{DiskTypeDisk, "", "", false},
+		{DiskTypePartition, "sdb", "", false},
+		{DiskTypeLoop, "", "", false},
+		{DiskTypeMD, "", "", false},
+		{DiskTypeDM, "", "vg0-root", false},
+		{DiskTypeZRAM, "", "", false},
+		{DiskTypeRAM, "", "", false},
+		{DiskTypeROM, "", "", true},
+		{DiskTypeOther, "", "", false},
+		{DiskTypeNBD, "", "", false},
+		{DiskTypeOther, "", "", false},
+	}
+
+	if len(devices) != len(expected) {
+		t.Fatal("unexpected device count", len(devices))
+	}
+
+	for i, d := range devices {
+
+		if d.Name != stats[i].Name {
+			t.Error("unexpected device order", i, d.Name)
+		}
+
+		got := expectation{d.Type, d.Parent, d.DMName, d.Rotational}
+
+		if got != expected[i] {
+			t.Error("unexpected classification of", d.Name, got)
+		}
+	}
+
+	disks := PhysicalDisks(devices)
+
+	if len(disks) != 2 || disks[0].Name != "sda" || disks[1].Name != "sdb" {
+		t.Error("unexpected physical disks", disks)
+	}
+}
@@ -0,0 +1,147 @@
+package linuxtool
+
+import (
+	"math"
+	"time"
+)
+
+// DiskIOStat is the disk activity between two DiskStat samples, with the
+// metrics of iostat -x. The iostat column is given next to each field.
+type DiskIOStat struct {
+	Major             int     `json:"major"`
+	Minor             int     `json:"minor"`
+	Name              string  `json:"name"`
+	ReadsPerSec       float64 `json:"reads_per_sec"`        // r/s
+	WritesPerSec      float64 `json:"writes_per_sec"`       // w/s
+	ReadKBPerSec      float64 `json:"read_kb_per_sec"`      // rkB/s
+	WriteKBPerSec     float64 `json:"write_kb_per_sec"`     // wkB/s
+	ReadMergesPerSec  float64 `json:"read_merges_per_sec"`  // rrqm/s
+	WriteMergesPerSec float64 `json:"write_merges_per_sec"` // wrqm/s
+	ReadAwait         float64 `json:"read_await"`           // r_await, milliseconds
+	WriteAwait        float64 `json:"write_await"`          // w_await, milliseconds
+	AvgQueueSize      float64 `json:"avg_queue_size"`       // aqu-sz
+	AvgRequestSize    float64 `json:"avg_request_size"`     // areq-sz, kB
+	Util              float64 `json:"util"`                 // %util
+
+	// Zero unless both samples have the discard fields, see DiskStat.HasDiscard.
+	DiscardsPerSec      float64 `json:"discards_per_sec"`       // d/s
+	DiscardKBPerSec     float64 `json:"discard_kb_per_sec"`     // dkB/s
+	DiscardMergesPerSec float64 `json:"discard_merges_per_sec"` // drqm/s
+	DiscardAwait        float64 `json:"discard_await"`          // d_await, milliseconds
+
+	// Zero unless both samples have the flush fields, see DiskStat.HasFlush.
+	FlushesPerSec float64 `json:"flushes_per_sec"` // f/s
+	FlushAwait    float64 `json:"flush_await"`     // f_await, milliseconds
+}
+
+// CalculateDiskIOStats returns the activity of every device found in both the
+// prev and curr samples, taken elapsed apart, in the order of curr. Devices
+// which appeared or disappeared between the samples are left out.
+func CalculateDiskIOStats(prev, curr []DiskStat, elapsed time.Duration) []DiskIOStat {
+
+	type device struct {
+		major int
+		minor int
+		name  string
+	}
+
+	before := make(map[device]*DiskStat, len(prev))
+
+	for i := range prev {
+		before[device{prev[i].Major, prev[i].Minor, prev[i].Name}] = &prev[i]
+	}
+
+	results := make([]DiskIOStat, 0, len(curr))
+
+	for i := range curr {
+		if p, ok := before[device{curr[i].Major, curr[i].Minor, curr[i].Name}]; ok {
+			results = append(results, calculateDiskIOStat(p, &curr[i], elapsed))
+		}
+	}
+
+	return results
+}
+
+func calculateDiskIOStat(prev, curr *DiskStat, elapsed time.Duration) DiskIOStat {
+
+	readIOs := counterDelta(prev.ReadIOs, curr.ReadIOs)
+	writeIOs := counterDelta(prev.WriteIOs, curr.WriteIOs)
+	readSectors := counterDelta(prev.ReadSectors, curr.ReadSectors)
+	writeSectors := counterDelta(prev.WriteSectors, curr.WriteSectors)
+	readTicks := counterDelta(prev.ReadTicks, curr.ReadTicks)
+	writeTicks := counterDelta(prev.WriteTicks, curr.WriteTicks)
+
+	var discardIOs, discardMerges, discardSectors, flushIOs uint64
+
+	if prev.HasDiscard() && curr.HasDiscard() {
+		discardIOs = counterDelta(prev.DiscardIOs, curr.DiscardIOs)
+		discardMerges = counterDelta(prev.DiscardMerges, curr.DiscardMerges)
+		discardSectors = counterDelta(prev.DiscardSectors, curr.DiscardSectors)
+	}
+
+	if prev.HasFlush() && curr.HasFlush() {
+		flushIOs = counterDelta(prev.FlushIOs, curr.FlushIOs)
+	}
+
+	s := DiskIOStat{
+		Major: curr.Major,
+		Minor: curr.Minor,
+		Name:  curr.Name,
+	}
+
+	seconds := elapsed.Seconds()
+	millis := seconds * 1000
+
+	if seconds > 0 {
+		s.ReadsPerSec = float64(readIOs) / seconds
+		s.WritesPerSec = float64(writeIOs) / seconds
+		s.ReadKBPerSec = float64(readSectors) / 2 / seconds
+		s.WriteKBPerSec = float64(writeSectors) / 2 / seconds
+		s.ReadMergesPerSec = float64(counterDelta(prev.ReadMerges, curr.ReadMerges)) / seconds
+		s.WriteMergesPerSec = float64(counterDelta(prev.WriteMerges, curr.WriteMerges)) / seconds
+		s.AvgQueueSize = float64(counterDelta(prev.TimeInQueue, curr.TimeInQueue)) / millis
+		s.Util = math.Min(float64(counterDelta(prev.IOTicks, curr.IOTicks))*100/millis, 100)
+		s.DiscardsPerSec = float64(discardIOs) / seconds
+		s.DiscardKBPerSec = float64(discardSectors) / 2 / seconds
+		s.DiscardMergesPerSec = float64(discardMerges) / seconds
+		s.FlushesPerSec = float64(flushIOs) / seconds
+	}
+
+	if readIOs > 0 {
+		s.ReadAwait = float64(readTicks) / float64(readIOs)
+	}
+
+	if writeIOs > 0 {
+		s.WriteAwait = float64(writeTicks) / float64(writeIOs)
+	}
+
+	if discardIOs > 0 {
+		s.DiscardAwait = float64(counterDelta(prev.DiscardTicks, curr.DiscardTicks)) / float64(discardIOs)
+	}
+
+	if flushIOs > 0 {
+		s.FlushAwait = float64(counterDelta(prev.FlushTicks, curr.FlushTicks)) / float64(flushIOs)
+	}
+
+	if ios := readIOs + writeIOs + discardIOs; ios > 0 {
+		s.AvgRequestSize = float64(readSectors+writeSectors+discardSectors) / 2 / float64(ios)
+	}
+
+	return s
+}
+
+// counterDelta returns curr - prev for a counter which may have wrapped around.
+// The kernel keeps the diskstats counters in unsigned longs, which are 32 bit
+// wide on 32 bit platforms.
+func counterDelta(prev, curr uint64) uint64 {
+
+	if curr >= prev {
+		return curr - prev
+	}
+
+	if prev <= math.MaxUint32 {
+		return curr + (math.MaxUint32 - prev) + 1
+	}
+
+	return curr + (math.MaxUint64 - prev) + 1
+}
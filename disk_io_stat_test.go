@@ -0,0 +1,89 @@
+package linuxtool
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCalculateDiskIOStats(t *testing.T) {
+
+	prev := []DiskStat{
+		{Major: 8, Minor: 0, Name: "sda", ReadIOs: 1000, ReadMerges: 10, ReadSectors: 8000, ReadTicks: 500,
+			WriteIOs: math.MaxUint32 - 99, WriteMerges: 20, WriteSectors: 16000, WriteTicks: 1000, IOTicks: 3000, TimeInQueue: 4000},
+		{Major: 8, Minor: 16, Name: "sdb"},
+	}
+
+	curr := []DiskStat{
+		{Major: 7, Minor: 0, Name: "loop0", ReadIOs: 10},
+		{Major: 8, Minor: 0, Name: "sda", ReadIOs: 1200, ReadMerges: 30, ReadSectors: 9600, ReadTicks: 900,
+			WriteIOs: 100, WriteMerges: 60, WriteSectors: 17600, WriteTicks: 1800, IOTicks: 4000, TimeInQueue: 6000},
+	}
+
+	stats := CalculateDiskIOStats(prev, curr, 2*time.Second)
+
+	// 200 reads and, after the 32 bit write counter wrapped, 200 writes in 2s
+	expected := []DiskIOStat{
+		{
+			Major: 8, Minor: 0, Name: "sda",
+			ReadsPerSec: 100, WritesPerSec: 100,
+			ReadKBPerSec: 400, WriteKBPerSec: 400,
+			ReadMergesPerSec: 10, WriteMergesPerSec: 20,
+			ReadAwait: 2, WriteAwait: 4,
+			AvgQueueSize: 1, AvgRequestSize: 4, Util: 50,
+		},
+	}
+
+	if !reflect.DeepEqual(stats, expected) {
+		t.Errorf("not equal to expected %+v", expected)
+	}
+
+	t.Logf("%+v", stats)
+}
+
+func TestCounterDelta(t *testing.T) {
+
+	if d := counterDelta(10, 25); d != 15 {
+		t.Error("unexpected delta", d)
+	}
+
+	if d := counterDelta(math.MaxUint32-4, 5); d != 10 {
+		t.Error("unexpected 32 bit wrap delta", d)
+	}
+
+	if d := counterDelta(math.MaxUint64-4, 5); d != 10 {
+		t.Error("unexpected 64 bit wrap delta", d)
+	}
+}
+
+func TestCalculateDiskIOStatsDiscardFlush(t *testing.T) {
+
+	prev := []DiskStat{
+		{Major: 259, Minor: 0, Name: "nvme0n1", DiscardIOs: 10, DiscardSectors: 2048, DiscardTicks: 5, FlushIOs: 100, FlushTicks: 40, StatFields: 17},
+		{Major: 8, Minor: 0, Name: "sda", StatFields: 11},
+	}
+
+	curr := []DiskStat{
+		{Major: 259, Minor: 0, Name: "nvme0n1", DiscardIOs: 20, DiscardMerges: 5, DiscardSectors: 4096, DiscardTicks: 25, FlushIOs: 150, FlushTicks: 90, StatFields: 17},
+		{Major: 8, Minor: 0, Name: "sda", DiscardIOs: 10, StatFields: 15},
+	}
+
+	stats := CalculateDiskIOStats(prev, curr, time.Second)
+
+	expected := []DiskIOStat{
+		{
+			Major: 259, Minor: 0, Name: "nvme0n1",
+			AvgRequestSize: 102.4,
+			DiscardsPerSec: 10, DiscardKBPerSec: 1024, DiscardMergesPerSec: 5, DiscardAwait: 2,
+			FlushesPerSec: 50, FlushAwait: 1,
+		},
+		{Major: 8, Minor: 0, Name: "sda"},
+	}
+
+	if !reflect.DeepEqual(stats, expected) {
+		t.Errorf("not equal to expected %+v", expected)
+	}
+
+	t.Logf("%+v", stats)
+}
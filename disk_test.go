@@ -1,6 +1,9 @@
 package linuxtool
 
-import "testing"
+import (
+	"syscall"
+	"testing"
+)
 
 func TestDisk(t *testing.T) {
 	disk, err := ReadDisk("/")
@@ -11,4 +14,47 @@ func TestDisk(t *testing.T) {
 	if disk.Free <= 0 {
 		t.Log("no good")
 	}
+	if disk.Available > disk.Free {
+		t.Error("available space should not exceed free space")
+	}
+}
+
+func TestDiskStatfs(t *testing.T) {
+	disk := newDisk(&syscall.Statfs_t{
+		Type:   0xEF53,
+		Bsize:  4096,
+		Frsize: 1024,
+		Blocks: 1000,
+		Bfree:  100,
+		Bavail: 50,
+		Files:  300,
+		Ffree:  200,
+		Flags:  StReadOnly | StNoExec,
+	})
+
+	if disk.All != 1024000 || disk.Used != 921600 || disk.Free != 102400 || disk.Available != 51200 {
+		t.Errorf("unexpected space %+v", disk)
+	}
+	if disk.InodesTotal != 300 || disk.InodesUsed != 100 || disk.FSType != 0xEF53 {
+		t.Errorf("unexpected inodes %+v", disk)
+	}
+	if !disk.ReadOnly || !disk.HasFlag(StNoExec) || disk.HasFlag(StNoSuid) {
+		t.Errorf("unexpected flags %+v", disk)
+	}
+	// 900 / 950 = 94.7%
+	if p := disk.UsedPercent(); p != 95 {
+		t.Error("unexpected used percent", p)
+	}
+	// 100 / 300 = 33.3%
+	if p := disk.InodesUsedPercent(); p != 34 {
+		t.Error("unexpected inodes used percent", p)
+	}
+	if p := (&Disk{}).UsedPercent(); p != 0 {
+		t.Error("unexpected used percent", p)
+	}
+	// Some network filesystems and btrfs report more free than total.
+	disk = newDisk(&syscall.Statfs_t{Frsize: 4096, Blocks: 10, Bfree: 12, Bavail: 12, Files: 5, Ffree: 7})
+	if disk.Used != 0 || disk.InodesUsed != 0 {
+		t.Errorf("unexpected usage %+v", disk)
+	}
 }
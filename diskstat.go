@@ -31,41 +31,141 @@ type DiskStat struct {
 	InFlight     uint64 `json:"in_flight"`     // number of I/Os currently in flight
 	IOTicks      uint64 `json:"io_ticks"`      // total time this block device has been active in milliseconds
 	TimeInQueue  uint64 `json:"time_in_queue"` // total wait time for all requests in milliseconds
+
+	// Since Linux 4.18
+	DiscardIOs     uint64 `json:"discard_ios"`     // number of discard I/Os processed
+	DiscardMerges  uint64 `json:"discard_merges"`  // number of discard I/Os merged with in-queue I/O
+	DiscardSectors uint64 `json:"discard_sectors"` // number of 512 byte sectors discarded
+	DiscardTicks   uint64 `json:"discard_ticks"`   // total wait time for discard requests in milliseconds
+
+	// Since Linux 5.5
+	FlushIOs   uint64 `json:"flush_ios"`   // number of flush I/Os processed
+	FlushTicks uint64 `json:"flush_ticks"` // total wait time for flush requests in milliseconds
+
+	// Number of statistics fields found on the line: 11, 15 with the discard
+	// fields or 17 with the flush fields as well.
+	StatFields int `json:"stat_fields"`
 }
 
+const (
+	diskStatBaseFields    = 11
+	diskStatDiscardFields = 15
+	diskStatFlushFields   = 17
+)
+
 // ReadDiskStats reads and parses the file.
 //
 // Note:
-// * Assumes a well formed file and will panic if it isn't.
+// * A line with fewer than 14 fields is reported as a *ParseError.
+// * The discard and flush fields of newer kernels are read when present, see
+//   DiskStat.StatFields.
 func ReadDiskStats(path string) ([]DiskStat, error) {
+	return readDiskStats(path, nil)
+}
+
+func readDiskStats(path string, onError ParseErrorHandler) ([]DiskStat, error) {
 	data, err := ioutil.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
+	p := newParser(path, onError)
 	devices := strings.Split(string(data), "\n")
-	results := make([]DiskStat, len(devices)-1)
-
-	for i := range results {
-		fields := strings.Fields(devices[i])
-		results[i].Major = ParseIntValue(fields[0])
-		results[i].Minor = ParseIntValue(fields[1])
-		results[i].Name = fields[2]
-		results[i].ReadIOs = ParseUint64(fields[3])
-		results[i].ReadMerges = ParseUint64(fields[4])
-		results[i].ReadSectors = ParseUint64(fields[5])
-		results[i].ReadTicks = ParseUint64(fields[6])
-		results[i].WriteIOs = ParseUint64(fields[7])
-		results[i].WriteMerges = ParseUint64(fields[8])
-		results[i].WriteSectors = ParseUint64(fields[9])
-		results[i].WriteTicks = ParseUint64(fields[10])
-		results[i].InFlight = ParseUint64(fields[11])
-		results[i].IOTicks = ParseUint64(fields[12])
-		results[i].TimeInQueue = ParseUint64(fields[13])
+	results := make([]DiskStat, 0, len(devices))
+
+	for i, device := range devices {
+		fields := strings.Fields(device)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 14 {
+			if err := p.fail(i+1, "", device, ErrTooFewFields); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		stat, err := parseDiskStat(fields)
+		if err != nil {
+			if err := p.fail(i+1, "", device, err); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		results = append(results, *stat)
 	}
 
 	return results, nil
 }
 
+var diskStatFields = []string{
+	"major", "minor", "name",
+	"read_ios", "read_merges", "read_sectors", "read_ticks",
+	"write_ios", "write_merges", "write_sectors", "write_ticks",
+	"in_flight", "io_ticks", "time_in_queue",
+	"discard_ios", "discard_merges", "discard_sectors", "discard_ticks",
+	"flush_ios", "flush_ticks",
+}
+
+func parseDiskStat(fields []string) (*DiskStat, error) {
+	var n [20]uint64
+	var err error
+	stats := len(fields) - 3
+	if stats > diskStatFlushFields {
+		stats = diskStatFlushFields
+	}
+	for i := 3; i < stats+3; i++ {
+		if n[i], err = ParseUint(fields[i]); err != nil {
+			return nil, &fieldError{diskStatFields[i], err}
+		}
+	}
+	var major, minor int64
+	if major, err = ParseInt32(fields[0]); err != nil {
+		return nil, &fieldError{diskStatFields[0], err}
+	}
+	if minor, err = ParseInt32(fields[1]); err != nil {
+		return nil, &fieldError{diskStatFields[1], err}
+	}
+	ds := &DiskStat{
+		Major:        int(major),
+		Minor:        int(minor),
+		Name:         fields[2],
+		ReadIOs:      n[3],
+		ReadMerges:   n[4],
+		ReadSectors:  n[5],
+		ReadTicks:    n[6],
+		WriteIOs:     n[7],
+		WriteMerges:  n[8],
+		WriteSectors: n[9],
+		WriteTicks:   n[10],
+		InFlight:     n[11],
+		IOTicks:      n[12],
+		TimeInQueue:  n[13],
+		StatFields:   diskStatBaseFields,
+	}
+	if stats >= diskStatDiscardFields {
+		ds.DiscardIOs = n[14]
+		ds.DiscardMerges = n[15]
+		ds.DiscardSectors = n[16]
+		ds.DiscardTicks = n[17]
+		ds.StatFields = diskStatDiscardFields
+	}
+	if stats >= diskStatFlushFields {
+		ds.FlushIOs = n[18]
+		ds.FlushTicks = n[19]
+		ds.StatFields = diskStatFlushFields
+	}
+	return ds, nil
+}
+
+// HasDiscard reports whether the discard fields were present (Linux 4.18+).
+func (ds *DiskStat) HasDiscard() bool {
+	return ds.StatFields >= diskStatDiscardFields
+}
+
+// HasFlush reports whether the flush fields were present (Linux 5.5+).
+func (ds *DiskStat) HasFlush() bool {
+	return ds.StatFields >= diskStatFlushFields
+}
+
 // GetReadBytes returns the number of bytes read.
 func (ds *DiskStat) GetReadBytes() int64 {
 	return int64(ds.ReadSectors) * 512
@@ -95,3 +195,18 @@ func (ds *DiskStat) GetIOTicks() time.Duration {
 func (ds *DiskStat) GetTimeInQueue() time.Duration {
 	return time.Duration(ds.TimeInQueue) * time.Millisecond
 }
+
+// GetDiscardBytes returns the number of bytes discarded.
+func (ds *DiskStat) GetDiscardBytes() int64 {
+	return int64(ds.DiscardSectors) * 512
+}
+
+// GetDiscardTicks returns the duration waited for discard requests.
+func (ds *DiskStat) GetDiscardTicks() time.Duration {
+	return time.Duration(ds.DiscardTicks) * time.Millisecond
+}
+
+// GetFlushTicks returns the duration waited for flush requests.
+func (ds *DiskStat) GetFlushTicks() time.Duration {
+	return time.Duration(ds.FlushTicks) * time.Millisecond
+}
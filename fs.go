@@ -0,0 +1,314 @@
+package linuxtool
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+const (
+	DefaultProcRoot = "/proc"
+	DefaultSysRoot  = "/sys"
+)
+
+// ProcFS is a proc filesystem mounted at Root.
+//
+// Its methods are thin wrappers around the Read* functions which resolve the
+// file paths relative to Root, so an agent running in a container can read the
+// host's proc mounted at, e.g., /host/proc.
+//
+// OnParseError decides what the readers do with content they cannot parse; a
+// nil handler aborts on the first *ParseError, like the Read* functions.
+type ProcFS struct {
+	Root         string            `json:"root"`
+	OnParseError ParseErrorHandler `json:"-"`
+}
+
+// SysFS is a sysfs filesystem mounted at Root.
+type SysFS struct {
+	Root string `json:"root"`
+}
+
+// NewProcFS returns a ProcFS for the proc filesystem mounted at root.
+func NewProcFS(root string) ProcFS {
+	return ProcFS{Root: root}
+}
+
+// NewSysFS returns a SysFS for the sysfs filesystem mounted at root.
+func NewSysFS(root string) SysFS {
+	return SysFS{Root: root}
+}
+
+// DefaultProcFS returns a ProcFS for /proc.
+func DefaultProcFS() ProcFS {
+	return NewProcFS(DefaultProcRoot)
+}
+
+// DefaultSysFS returns a SysFS for /sys.
+func DefaultSysFS() SysFS {
+	return NewSysFS(DefaultSysRoot)
+}
+
+// Path joins elem to the root of the filesystem.
+func (fs ProcFS) Path(elem ...string) string {
+	return filepath.Join(append([]string{fs.Root}, elem...)...)
+}
+
+// Path joins elem to the root of the filesystem.
+func (fs SysFS) Path(elem ...string) string {
+	return filepath.Join(append([]string{fs.Root}, elem...)...)
+}
+
+func (fs ProcFS) processPath(pid uint64, elem ...string) string {
+	return fs.Path(append([]string{strconv.FormatUint(pid, 10)}, elem...)...)
+}
+
+// CPUInfo reads cpuinfo, see ReadCPUInfo.
+func (fs ProcFS) CPUInfo() (*CPUInfo, error) {
+	return readCPUInfo(fs.Path("cpuinfo"), fs.OnParseError)
+}
+
+// DiskStats reads diskstats, see ReadDiskStats.
+func (fs ProcFS) DiskStats() ([]DiskStat, error) {
+	return readDiskStats(fs.Path("diskstats"), fs.OnParseError)
+}
+
+// Interrupts reads interrupts, see ReadInterrupts.
+func (fs ProcFS) Interrupts() (*Interrupts, error) {
+	return readInterrupts(fs.Path("interrupts"), fs.OnParseError)
+}
+
+// LoadAvg reads loadavg, see ReadLoadAvg.
+func (fs ProcFS) LoadAvg() (*LoadAvg, error) {
+	return readLoadAvg(fs.Path("loadavg"), fs.OnParseError)
+}
+
+// MemInfo reads meminfo, see ReadMemInfo.
+func (fs ProcFS) MemInfo() (*MemInfo, error) {
+	return readMemInfo(fs.Path("meminfo"), fs.OnParseError)
+}
+
+// Mounts reads mounts, see ReadMounts.
+func (fs ProcFS) Mounts() (*Mounts, error) {
+	return readMounts(fs.Path("mounts"), fs.OnParseError)
+}
+
+// NetDev reads net/dev, see ReadNetworkStat.
+func (fs ProcFS) NetDev() ([]NetworkStat, error) {
+	return readNetworkStat(fs.Path("net", "dev"), fs.OnParseError)
+}
+
+// NetStat reads net/netstat, see ReadNetStat.
+func (fs ProcFS) NetStat() (*NetStat, error) {
+	return readNetStat(fs.Path("net", "netstat"), fs.OnParseError)
+}
+
+// NetTCP reads the IPv4 sockets of net/tcp, see ReadNetTCPSockets.
+func (fs ProcFS) NetTCP() (*NetTCPSockets, error) {
+	return readNetTCPSockets(fs.Path("net", "tcp"), NetIPv4Decoder, fs.OnParseError)
+}
+
+// NetTCP6 reads the IPv6 sockets of net/tcp6, see ReadNetTCPSockets.
+func (fs ProcFS) NetTCP6() (*NetTCPSockets, error) {
+	return readNetTCPSockets(fs.Path("net", "tcp6"), NetIPv6Decoder, fs.OnParseError)
+}
+
+// NetUDP reads the IPv4 sockets of net/udp, see ReadNetUDPSockets.
+func (fs ProcFS) NetUDP() (*NetUDPSockets, error) {
+	return readNetUDPSockets(fs.Path("net", "udp"), NetIPv4Decoder, fs.OnParseError)
+}
+
+// NetUDP6 reads the IPv6 sockets of net/udp6, see ReadNetUDPSockets.
+func (fs ProcFS) NetUDP6() (*NetUDPSockets, error) {
+	return readNetUDPSockets(fs.Path("net", "udp6"), NetIPv6Decoder, fs.OnParseError)
+}
+
+// Snmp reads net/snmp, see ReadSnmp.
+func (fs ProcFS) Snmp() (*Snmp, error) {
+	return readSnmp(fs.Path("net", "snmp"), fs.OnParseError)
+}
+
+// SockStat reads net/sockstat, see ReadSockStat.
+func (fs ProcFS) SockStat() (*SockStat, error) {
+	return readSockStat(fs.Path("net", "sockstat"), fs.OnParseError)
+}
+
+// Stat reads stat, see ReadStat.
+func (fs ProcFS) Stat() (*Stat, error) {
+	return readStat(fs.Path("stat"), fs.OnParseError)
+}
+
+// Uptime reads uptime, see ReadUptime.
+func (fs ProcFS) Uptime() (*Uptime, error) {
+	return readUptime(fs.Path("uptime"), fs.OnParseError)
+}
+
+// VMStat reads vmstat, see ReadVMStat.
+func (fs ProcFS) VMStat() (*VMStat, error) {
+	return readVMStat(fs.Path("vmstat"), fs.OnParseError)
+}
+
+// MaxPID reads sys/kernel/pid_max, see ReadMaxPID.
+func (fs ProcFS) MaxPID() (uint64, error) {
+	return readMaxPID(fs.Path("sys", "kernel", "pid_max"), fs.OnParseError)
+}
+
+// PIDs returns the pids of all processes, in ascending order.
+func (fs ProcFS) PIDs() ([]uint64, error) {
+	return ListPID(fs.Root, ^uint64(0))
+}
+
+// WalkPID calls fn for each pid of Root, see WalkPID.
+func (fs ProcFS) WalkPID(fn func(pid uint64) error) error {
+	return WalkPID(fs.Root, fn)
+}
+
+// WalkTGID calls fn for each thread-group leader of Root, see WalkTGID.
+func (fs ProcFS) WalkTGID(fn func(pid uint64) error) error {
+	return WalkTGID(fs.Root, fn)
+}
+
+// Process reads the process pid, see ReadProcess.
+func (fs ProcFS) Process(pid uint64) (*Process, error) {
+	return readProcess(pid, fs.Root, DefaultProcessParts, fs.OnParseError)
+}
+
+// ProcessParts reads parts of the process pid, see ReadProcessParts.
+func (fs ProcFS) ProcessParts(pid uint64, parts ProcessParts) (*Process, error) {
+	return readProcess(pid, fs.Root, parts, fs.OnParseError)
+}
+
+// ProcessStat reads the stat of the process pid, see ReadProcessStat.
+func (fs ProcFS) ProcessStat(pid uint64) (*ProcessStat, error) {
+	return readProcessStat(fs.processPath(pid, "stat"), fs.OnParseError)
+}
+
+// ProcessStatm reads the statm of the process pid, see ReadProcessStatm.
+func (fs ProcFS) ProcessStatm(pid uint64) (*ProcessStatm, error) {
+	return readProcessStatm(fs.processPath(pid, "statm"), fs.OnParseError)
+}
+
+// ProcessStatus reads the status of the process pid, see ReadProcessStatus.
+func (fs ProcFS) ProcessStatus(pid uint64) (*ProcessStatus, error) {
+	return readProcessStatus(fs.processPath(pid, "status"), fs.OnParseError)
+}
+
+// ProcessIO reads the io of the process pid, see ReadProcessIO.
+func (fs ProcFS) ProcessIO(pid uint64) (*ProcessIO, error) {
+	return readProcessIO(fs.processPath(pid, "io"), fs.OnParseError)
+}
+
+// MountInfo reads the mountinfo of the process pid, see ReadMountInfo.
+func (fs ProcFS) MountInfo(pid uint64) ([]MountInfo, error) {
+	return readMountInfo(fs.processPath(pid, "mountinfo"), fs.OnParseError)
+}
+
+// ProcessCmdline reads the command line of the process pid, see
+// ReadProcessCmdline.
+func (fs ProcFS) ProcessCmdline(pid uint64) (string, error) {
+	return ReadProcessCmdline(fs.processPath(pid, "cmdline"))
+}
+
+// ProcessArgv reads the arguments of the process pid, see ReadProcessArgv.
+func (fs ProcFS) ProcessArgv(pid uint64) ([]string, error) {
+	return ReadProcessArgv(fs.processPath(pid, "cmdline"))
+}
+
+// ProcessEnviron reads the environment of the process pid, see
+// ReadProcessEnviron.
+func (fs ProcFS) ProcessEnviron(pid uint64) (map[string]string, error) {
+	return ReadProcessEnviron(fs.processPath(pid, "environ"))
+}
+
+// DiskDevices classifies stats, as returned by ProcFS.DiskStats, using the
+// block device information of this sysfs.
+func (fs SysFS) DiskDevices(stats []DiskStat) ([]DiskDevice, error) {
+	return ReadDiskDevices(fs.Root, stats)
+}
+
+// FSUsage reports the usage of the filesystems mounted in the mount namespace
+// of the calling process, see ReadFSUsage.
+func (fs ProcFS) FSUsage(opts FSUsageOptions) ([]FSUsage, error) {
+	return readFSUsage(fs.Path("self", "mountinfo"), opts, fs.OnParseError)
+}
+
+// ProcessTree reads the processes and builds their tree, see
+// ReadProcessTree.
+func (fs ProcFS) ProcessTree() (*ProcessTree, error) {
+	return readProcessTree(fs.Root, fs.OnParseError)
+}
+
+// ThreadIDs returns the thread ids of the process pid, see ListTID.
+func (fs ProcFS) ThreadIDs(pid uint64) ([]uint64, error) {
+	return ListTID(pid, fs.Root)
+}
+
+// Thread reads the thread tid of the process pid, see ReadThread.
+func (fs ProcFS) Thread(pid uint64, tid uint64) (*Thread, error) {
+	return readThread(pid, tid, fs.Root, fs.OnParseError)
+}
+
+// Threads reads all threads of the process pid, see ReadThreads.
+func (fs ProcFS) Threads(pid uint64) ([]Thread, error) {
+	return readThreads(pid, fs.Root, fs.OnParseError)
+}
+
+// ProcessSmaps reads the smaps of the process pid, see ReadProcessSmaps.
+func (fs ProcFS) ProcessSmaps(pid uint64) ([]ProcessSmap, error) {
+	return readProcessSmaps(fs.processPath(pid, "smaps"), fs.OnParseError)
+}
+
+// ProcessSmapsRollup reads the smaps_rollup of the process pid, see
+// ReadProcessSmapsRollup.
+func (fs ProcFS) ProcessSmapsRollup(pid uint64) (*ProcessSmap, error) {
+	return readProcessSmapsRollup(fs.processPath(pid, "smaps_rollup"), fs.OnParseError)
+}
+
+// ProcessMemoryUsage reads the memory usage of the process pid, see
+// ReadProcessMemoryUsage.
+func (fs ProcFS) ProcessMemoryUsage(pid uint64) (*ProcessMemoryUsage, error) {
+	return readProcessMemoryUsage(pid, fs.Root, fs.OnParseError)
+}
+
+// ProcessMaps reads the maps of the process pid, see ReadProcessMaps.
+func (fs ProcFS) ProcessMaps(pid uint64) ([]ProcessMap, error) {
+	return readProcessMaps(fs.processPath(pid, "maps"), fs.OnParseError)
+}
+
+// StaleProcesses lists the processes using deleted libraries, see
+// FindStaleProcesses.
+func (fs ProcFS) StaleProcesses() ([]StaleProcess, error) {
+	return findStaleProcesses(fs.Root, fs.OnParseError)
+}
+
+// ProcessLimits reads the limits of the process pid, see ReadProcessLimits.
+func (fs ProcFS) ProcessLimits(pid uint64) (*ProcessLimits, error) {
+	return readProcessLimits(fs.processPath(pid, "limits"), fs.OnParseError)
+}
+
+// ProcessHeadroom compares the limits of the process pid with its usage, see
+// ReadProcessHeadroom.
+func (fs ProcFS) ProcessHeadroom(pid uint64) (*ProcessHeadroom, error) {
+	return readProcessHeadroom(pid, fs.Root, fs.OnParseError, CountProcessFDs)
+}
+
+// ProcessFDs lists the open fds of the process pid, see ReadProcessFDs.
+func (fs ProcFS) ProcessFDs(pid uint64) ([]ProcessFD, error) {
+	return readProcessFDs(pid, fs.Root, fs.OnParseError)
+}
+
+// DeletedFiles lists the deleted files held open, see FindDeletedFiles.
+func (fs ProcFS) DeletedFiles() ([]DeletedFile, error) {
+	return findDeletedFiles(fs.Root, fs.OnParseError)
+}
+
+// FileNr reads sys/fs/file-nr, see ReadFileNr.
+func (fs ProcFS) FileNr() (*FileNr, error) {
+	return readFileNr(fs.Path("sys", "fs", "file-nr"), fs.OnParseError)
+}
+
+// SocketResolver scans the fds of all processes for sockets, see
+// NewSocketResolver.
+func (fs ProcFS) SocketResolver() (*SocketResolver, error) {
+	return newSocketResolver(fs.Root, fs.OnParseError, os.Readlink)
+}
@@ -0,0 +1,74 @@
+package linuxtool
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestProcFSPath(t *testing.T) {
+
+	fs := NewProcFS("/host/proc")
+
+	if p := fs.Path("net", "tcp"); p != "/host/proc/net/tcp" {
+		t.Error("unexpected path", p)
+	}
+
+	if p := fs.processPath(3323, "stat"); p != "/host/proc/3323/stat" {
+		t.Error("unexpected path", p)
+	}
+
+	if p := DefaultSysFS().Path("block", "sda"); p != "/sys/block/sda" {
+		t.Error("unexpected path", p)
+	}
+}
+
+func TestProcFS(t *testing.T) {
+
+	fs := NewProcFS("proc")
+
+	stat, err := fs.Stat()
+
+	if err != nil {
+		t.Fatal("stat read fail", err)
+	}
+
+	if len(stat.CPUStats) != 8 {
+		t.Error("unexpected cpu count", len(stat.CPUStats))
+	}
+
+	pids, err := fs.PIDs()
+
+	if err != nil {
+		t.Fatal("list pid fail", err)
+	}
+
+	if !reflect.DeepEqual(pids, []uint64{884, 3323, 4854, 5811}) {
+		t.Error("unexpected pids", pids)
+	}
+
+	p, err := fs.Process(3323)
+
+	if err != nil {
+		t.Fatal("process read fail", err)
+	}
+
+	expected, err := ReadProcess(3323, "proc")
+
+	if err != nil {
+		t.Fatal("process read fail", err)
+	}
+
+	if !reflect.DeepEqual(p, expected) {
+		t.Error("not equal to expected", expected)
+	}
+
+	stat884, err := fs.ProcessStat(884)
+
+	if err != nil {
+		t.Fatal("process stat read fail", err)
+	}
+
+	if stat884.Comm != "(rs:main Q:Reg)" {
+		t.Error("unexpected comm", stat884.Comm)
+	}
+}
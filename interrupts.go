@@ -16,34 +16,44 @@ type Interrupts struct {
 }
 
 func ReadInterrupts(path string) (*Interrupts, error) {
+	return readInterrupts(path, nil)
+}
+
+func readInterrupts(path string, onError ParseErrorHandler) (*Interrupts, error) {
 	b, err := ioutil.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
+	p := newParser(path, onError)
 	content := string(b)
 	lines := strings.Split(content, "\n")
 	cpus := lines[0]
-	lines = append(lines[:0], lines[1:]...)
 	numCpus := len(strings.Fields(cpus))
 	interrupts := make([]Interrupt, 0)
-	for _, line := range lines {
+	for n, line := range lines[1:] {
 		fields := strings.Fields(line)
 		if len(fields) == 0 {
 			continue
 		}
+		name := strings.TrimSuffix(fields[0], ":")
 		counts := make([]uint64, 0)
 		i := 0
 		for ; i < numCpus; i++ {
 			if len(fields) <= i+1 {
 				break
 			}
-			count, err := ParseInt(fields[i+1])
-			if err != nil {
+			var count uint64
+			if count, err = ParseUint(fields[i+1]); err != nil {
+				break
+			}
+			counts = append(counts, count)
+		}
+		if err != nil {
+			if err = p.fail(n+2, name, line, err); err != nil {
 				return nil, err
 			}
-			counts = append(counts, uint64(count))
+			continue
 		}
-		name := strings.TrimSuffix(fields[0], ":")
 		description := strings.Join(fields[i+1:], " ")
 		interrupts = append(interrupts, Interrupt{
 			Name:        name,
@@ -18,7 +18,6 @@ package linuxtool
 // ref: https://linux.die.net/man/5/proc
 
 import (
-	"errors"
 	"io/ioutil"
 	"strings"
 )
@@ -33,6 +32,10 @@ type LoadAvg struct {
 }
 
 func ReadLoadAvg(path string) (*LoadAvg, error) {
+	return readLoadAvg(path, nil)
+}
+
+func readLoadAvg(path string, onError ParseErrorHandler) (*LoadAvg, error) {
 
 	b, err := ioutil.ReadFile(path)
 
@@ -40,43 +43,56 @@ func ReadLoadAvg(path string) (*LoadAvg, error) {
 		return nil, err
 	}
 
+	p := newParser(path, onError)
+
 	content := strings.TrimSpace(string(b))
 	fields := strings.Fields(content)
 
+	loadavg := LoadAvg{}
+
 	if len(fields) < 5 {
-		return nil, errors.New("Cannot parse loadavg: " + content)
+		if err = p.fail(1, "", content, ErrTooFewFields); err != nil {
+			return nil, err
+		}
+		return &loadavg, nil
 	}
 
-	process := strings.Split(fields[3], "/")
-
-	if len(process) != 2 {
-		return nil, errors.New("Cannot parse loadavg: " + content)
+	if process := strings.Split(fields[3], "/"); len(process) != 2 {
+		err = ErrTooFewFields
+	} else if loadavg.ProcessRunning, err = ParseUint(process[0]); err != nil {
+		err = &fieldError{"process_running", err}
+	} else if loadavg.ProcessTotal, err = ParseUint(process[1]); err != nil {
+		err = &fieldError{"process_total", err}
 	}
 
-	loadavg := LoadAvg{}
+	if err != nil {
+		if err = p.fail(1, "", content, err); err != nil {
+			return nil, err
+		}
+	}
 
 	if loadavg.Last1Min, err = ParseFloat(fields[0]); err != nil {
-		return nil, err
+		if err = p.fail(1, "last1min", content, err); err != nil {
+			return nil, err
+		}
 	}
 
 	if loadavg.Last5Min, err = ParseFloat(fields[1]); err != nil {
-		return nil, err
+		if err = p.fail(1, "last5min", content, err); err != nil {
+			return nil, err
+		}
 	}
 
 	if loadavg.Last15Min, err = ParseFloat(fields[2]); err != nil {
-		return nil, err
-	}
-
-	if loadavg.ProcessRunning, err = ParseUint(process[0]); err != nil {
-		return nil, err
-	}
-
-	if loadavg.ProcessTotal, err = ParseUint(process[1]); err != nil {
-		return nil, err
+		if err = p.fail(1, "last15min", content, err); err != nil {
+			return nil, err
+		}
 	}
 
 	if loadavg.LastPID, err = ParseUint(fields[4]); err != nil {
-		return nil, err
+		if err = p.fail(1, "last_pid", content, err); err != nil {
+			return nil, err
+		}
 	}
 
 	return &loadavg, nil
@@ -69,12 +69,18 @@ type MemInfo struct {
 }
 
 func ReadMemInfo(path string) (*MemInfo, error) {
+	return readMemInfo(path, nil)
+}
+
+func readMemInfo(path string, onError ParseErrorHandler) (*MemInfo, error) {
 	data, err := ioutil.ReadFile(path)
 
 	if err != nil {
 		return nil, err
 	}
 
+	p := newParser(path, onError)
+
 	lines := strings.Split(string(data), "\n")
 
 	// Maps a meminfo metric to its value (i.e. MemTotal --> 100000)
@@ -82,13 +88,26 @@ func ReadMemInfo(path string) (*MemInfo, error) {
 
 	var info = MemInfo{}
 
-	for _, line := range lines {
+	for i, line := range lines {
 		fields := strings.SplitN(line, ":", 2)
 		if len(fields) < 2 {
 			continue
 		}
 		valFields := strings.Fields(fields[1])
-		statMap[fields[0]] = ParseUint64(valFields[0])
+		if len(valFields) == 0 {
+			if err = p.fail(i+1, fields[0], line, ErrTooFewFields); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		val, err := ParseUint(valFields[0])
+		if err != nil {
+			if err = p.fail(i+1, fields[0], line, err); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		statMap[fields[0]] = val
 	}
 
 	elem := reflect.ValueOf(&info).Elem()
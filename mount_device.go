@@ -0,0 +1,304 @@
+package linuxtool
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// ErrNoBlockDevice is returned when the filesystem of a path is not backed by
+// a block device, such as tmpfs or NFS.
+var ErrNoBlockDevice = errors.New("no block device")
+
+// PathDevice is the block device backing a path.
+type PathDevice struct {
+	Path       string     `json:"path"`
+	MountPoint string     `json:"mountpoint"` // empty if the device was found by stat(2)
+	Major      int        `json:"major"`
+	Minor      int        `json:"minor"`
+	Name       string     `json:"name"`        // e.g. dm-0
+	Stat       *DiskStat  `json:"stat"`        // nil if the device is not in diskstats
+	Physical   []string   `json:"physical"`    // underlying whole disks, e.g. sda and sdb for an LVM on md
+	PhysicalIO []DiskStat `json:"physical_io"` // diskstats of Physical, when found
+}
+
+// DeviceResolver maps paths to the block devices, and their DiskStat, backing
+// them. It keeps the mount table and the diskstats it was created with, so
+// resolving many paths costs one scan.
+type DeviceResolver struct {
+	Mounts  []MountInfo
+	Stats   []DiskStat
+	SysPath string
+}
+
+// NewDeviceResolver reads the mount table of the calling process and the
+// diskstats from proc, and resolves devices in sys.
+func NewDeviceResolver(proc ProcFS, sys SysFS) (*DeviceResolver, error) {
+
+	mounts, err := readMountInfo(proc.Path("self", "mountinfo"), proc.OnParseError)
+
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := proc.DiskStats()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &DeviceResolver{Mounts: mounts, Stats: stats, SysPath: sys.Root}, nil
+}
+
+// Resolve returns the block device backing path.
+//
+// The device number is taken from the mount containing path, once its
+// symlinks are resolved, so that e.g. /var/lib/mysql linked to /data maps to
+// the mount of /data. Filesystems reporting an anonymous device, such as
+// btrfs, are resolved through the device node of the mount source. Paths
+// outside of the mount table are resolved by the st_dev of stat(2).
+func (r *DeviceResolver) Resolve(path string) (*PathDevice, error) {
+
+	d := &PathDevice{Path: path}
+
+	path, err := resolveSymlinks(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if m := FindMount(r.Mounts, path); m != nil {
+
+		d.MountPoint = m.MountPoint
+		d.Major = m.Major
+		d.Minor = m.Minor
+
+		if d.Major == 0 && strings.HasPrefix(m.Source, "/dev/") {
+			if st, err := statDevice(m.Source, true); err == nil {
+				d.Major, d.Minor = st.major, st.minor
+			}
+		}
+
+	} else {
+
+		st, err := statDevice(path, false)
+
+		if err != nil {
+			return nil, err
+		}
+
+		d.Major, d.Minor = st.major, st.minor
+	}
+
+	if d.Major == 0 {
+		return nil, ErrNoBlockDevice
+	}
+
+	name, err := BlockDeviceName(r.SysPath, d.Major, d.Minor)
+
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNoBlockDevice
+		}
+		return nil, err
+	}
+
+	d.Name = name
+
+	if d.Physical, err = PhysicalDevices(r.SysPath, name); err != nil {
+		return nil, err
+	}
+
+	for i := range r.Stats {
+		if r.Stats[i].Major == d.Major && r.Stats[i].Minor == d.Minor {
+			d.Stat = &r.Stats[i]
+			break
+		}
+	}
+
+	for _, disk := range d.Physical {
+		for i := range r.Stats {
+			if r.Stats[i].Name == disk {
+				d.PhysicalIO = append(d.PhysicalIO, r.Stats[i])
+				break
+			}
+		}
+	}
+
+	return d, nil
+}
+
+// BlockDeviceName returns the kernel name, as found in diskstats, of the block
+// device major:minor, using /sys/dev/block of the sysfs at sysPath.
+func BlockDeviceName(sysPath string, major, minor int) (string, error) {
+
+	link := filepath.Join(sysPath, "dev", "block", strconv.Itoa(major)+":"+strconv.Itoa(minor))
+
+	target, err := os.Readlink(link)
+
+	if err != nil {
+		return "", err
+	}
+
+	return strings.Replace(filepath.Base(target), "!", "/", -1), nil
+}
+
+// PhysicalDevices returns the whole disks below the block device name,
+// following the slaves of device mapper and md devices and going from
+// partitions to their disk. A disk is its own physical device.
+func PhysicalDevices(sysPath string, name string) ([]string, error) {
+
+	var disks []string
+
+	seen := make(map[string]bool)
+
+	var walk func(name string) error
+
+	walk = func(name string) error {
+
+		if seen[name] {
+			return nil
+		}
+
+		seen[name] = true
+
+		slaves, err := ioutil.ReadDir(filepath.Join(sysPath, "class", "block", name, "slaves"))
+
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		if len(slaves) > 0 {
+			for _, s := range slaves {
+				if err := walk(s.Name()); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		disk, err := partitionParent(sysPath, name)
+
+		if err != nil {
+			return err
+		}
+
+		if disk == "" {
+			disk = name
+		}
+
+		disk = strings.Replace(disk, "!", "/", -1)
+
+		for _, d := range disks {
+			if d == disk {
+				return nil
+			}
+		}
+
+		disks = append(disks, disk)
+
+		return nil
+	}
+
+	if err := walk(strings.Replace(name, "/", "!", -1)); err != nil {
+		return nil, err
+	}
+
+	return disks, nil
+}
+
+type deviceNumber struct {
+	major int
+	minor int
+}
+
+// statDevice returns st_rdev of a device node if rdev is set, st_dev of path
+// otherwise.
+// maxSymlinks is the number of symlinks resolveSymlinks follows before failing
+// with ELOOP, as the kernel does.
+const maxSymlinks = 40
+
+// resolveSymlinks returns the absolute path of path with its symlinks
+// resolved, like realpath -m: components which do not exist, or cannot be
+// read, are kept as they are.
+func resolveSymlinks(path string) (string, error) {
+
+	// Not filepath.Abs, which would clean .. before the symlinks are resolved.
+	if !filepath.IsAbs(path) {
+
+		wd, err := os.Getwd()
+
+		if err != nil {
+			return "", err
+		}
+
+		path = wd + "/" + path
+	}
+
+	resolved := "/"
+	rest := strings.Split(path, "/")
+	links := 0
+
+	for len(rest) > 0 {
+
+		name := rest[0]
+		rest = rest[1:]
+
+		switch name {
+		case "", ".":
+			continue
+		case "..":
+			resolved = filepath.Dir(resolved)
+			continue
+		}
+
+		next := filepath.Join(resolved, name)
+
+		target, err := os.Readlink(next)
+
+		if err != nil {
+			resolved = next
+			continue
+		}
+
+		if links++; links > maxSymlinks {
+			return "", &os.PathError{Op: "readlink", Path: path, Err: syscall.ELOOP}
+		}
+
+		if filepath.IsAbs(target) {
+			resolved = "/"
+		}
+
+		rest = append(strings.Split(target, "/"), rest...)
+	}
+
+	return resolved, nil
+}
+
+func statDevice(path string, rdev bool) (deviceNumber, error) {
+
+	var st syscall.Stat_t
+
+	if err := syscall.Stat(path, &st); err != nil {
+		return deviceNumber{}, err
+	}
+
+	dev := uint64(st.Dev)
+
+	if rdev {
+		if st.Mode&syscall.S_IFMT != syscall.S_IFBLK {
+			return deviceNumber{}, ErrNoBlockDevice
+		}
+		dev = uint64(st.Rdev)
+	}
+
+	// See gnu_dev_major and gnu_dev_minor of glibc.
+	return deviceNumber{
+		major: int((dev>>8)&0xfff | (dev>>32)&^0xfff),
+		minor: int(dev&0xff | (dev>>12)&^0xff),
+	}, nil
+}
@@ -0,0 +1,109 @@
+package linuxtool
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDeviceResolver(t *testing.T) {
+
+	mounts, err := ReadMountInfo("proc/mountinfo")
+
+	if err != nil {
+		t.Fatal("mountinfo read fail", err)
+	}
+
+	stats, err := ReadDiskStats("proc/diskstats_lvm")
+
+	if err != nil {
+		t.Fatal("diskstats read fail", err)
+	}
+
+	r := &DeviceResolver{Mounts: mounts, Stats: stats, SysPath: "sys"}
+
+	d, err := r.Resolve("/home/user/.profile")
+
+	if err != nil {
+		t.Fatal("resolve fail", err)
+	}
+
+	if d.MountPoint != "/home" || d.Name != "dm-0" || d.Stat == nil || d.Stat.Name != "dm-0" {
+		t.Errorf("unexpected device %+v", d)
+	}
+
+	if !reflect.DeepEqual(d.Physical, []string{"sda", "sdb"}) {
+		t.Error("unexpected physical disks", d.Physical)
+	}
+
+	if len(d.PhysicalIO) != 2 || d.PhysicalIO[0].Name != "sda" || d.PhysicalIO[1].Name != "sdb" {
+		t.Errorf("unexpected physical stats %+v", d.PhysicalIO)
+	}
+
+	d, err = r.Resolve("/mnt/backup disk/2020")
+
+	if err != nil {
+		t.Fatal("resolve fail", err)
+	}
+
+	if d.Name != "sdb1" || d.Stat.Name != "sdb1" || !reflect.DeepEqual(d.Physical, []string{"sdb"}) {
+		t.Errorf("unexpected device %+v", d)
+	}
+
+	if _, err = r.Resolve("/run/user/1000"); err != ErrNoBlockDevice {
+		t.Error("unexpected error", err)
+	}
+
+	// A symlink to a directory of another mount.
+	d, err = r.Resolve("proc/symlink_home/.profile")
+
+	if err != nil {
+		t.Fatal("resolve fail", err)
+	}
+
+	if d.Path != "proc/symlink_home/.profile" || d.MountPoint != "/home" || d.Name != "dm-0" {
+		t.Errorf("unexpected device %+v", d)
+	}
+}
+
+func TestResolveSymlinks(t *testing.T) {
+
+	tests := map[string]string{
+		"/home/user/.profile":        "/home/user/.profile",
+		"proc/symlink_home/.profile": "/home/user/.profile",
+		"proc/symlink_home/../x":     "/home/x",
+		"/nonexistent/./a//b/../c/":  "/nonexistent/a/c",
+	}
+
+	for path, expected := range tests {
+		if resolved, err := resolveSymlinks(path); err != nil || resolved != expected {
+			t.Error("unexpected resolution of", path, resolved, err)
+		}
+	}
+}
+
+func TestPhysicalDevices(t *testing.T) {
+
+	tests := map[string][]string{
+		"sda":   {"sda"},
+		"sda1":  {"sda"},
+		"md2":   {"sda", "sdb"},
+		"loop0": {"loop0"},
+	}
+
+	for name, expected := range tests {
+
+		disks, err := PhysicalDevices("sys", name)
+
+		if err != nil {
+			t.Fatal("physical devices read fail", err)
+		}
+
+		if !reflect.DeepEqual(disks, expected) {
+			t.Error("unexpected physical devices of", name, disks)
+		}
+	}
+
+	if name, err := BlockDeviceName("sys", 253, 0); err != nil || name != "dm-0" {
+		t.Error("unexpected device name", name, err)
+	}
+}
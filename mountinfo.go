@@ -0,0 +1,219 @@
+package linuxtool
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MountInfo is a line of /proc/<pid>/mountinfo.
+//
+// For more info see:
+// https://www.kernel.org/doc/Documentation/filesystems/proc.txt
+type MountInfo struct {
+	MountID       int               `json:"mount_id"`
+	ParentID      int               `json:"parent_id"`
+	Major         int               `json:"major"` // st_dev of the files in the mount
+	Minor         int               `json:"minor"`
+	Root          string            `json:"root"` // root of the mount within the filesystem, e.g. of bind mounts
+	MountPoint    string            `json:"mountpoint"`
+	Options       map[string]string `json:"options"` // per-mount options, flags map to ""
+	Shared        int               `json:"shared"`  // peer group, 0 if not shared
+	Master        int               `json:"master"`  // peer group of the master, 0 if not a slave
+	PropagateFrom int               `json:"propagate_from"`
+	Unbindable    bool              `json:"unbindable"`
+	FSType        string            `json:"fstype"`
+	Source        string            `json:"source"`
+	SuperOptions  map[string]string `json:"super_options"` // per-superblock options
+}
+
+var errNoSeparator = errors.New("missing optional fields separator")
+
+// ReadMountInfo reads and parses the mountinfo file, e.g. /proc/self/mountinfo.
+// Octal escapes, such as \040 for a space, are decoded in the root, the mount
+// point and the source.
+func ReadMountInfo(path string) ([]MountInfo, error) {
+	return readMountInfo(path, nil)
+}
+
+func readMountInfo(path string, onError ParseErrorHandler) ([]MountInfo, error) {
+	fin, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer fin.Close()
+
+	p := newParser(path, onError)
+
+	var mounts []MountInfo
+
+	n := 0
+	scanner := bufio.NewScanner(fin)
+	for scanner.Scan() {
+		n++
+		line := scanner.Text()
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 10 {
+			if err = p.fail(n, "", line, ErrTooFewFields); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		mount, err := parseMountInfo(fields)
+		if err != nil {
+			if err = p.fail(n, "", line, err); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		mounts = append(mounts, *mount)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return mounts, nil
+}
+
+func parseMountInfo(fields []string) (*MountInfo, error) {
+	var err error
+	var id, parent, major, minor int64
+
+	if id, err = ParseInt32(fields[0]); err != nil {
+		return nil, &fieldError{"mount_id", err}
+	}
+	if parent, err = ParseInt32(fields[1]); err != nil {
+		return nil, &fieldError{"parent_id", err}
+	}
+
+	dev := strings.SplitN(fields[2], ":", 2)
+	if len(dev) != 2 {
+		return nil, &fieldError{"major:minor", ErrTooFewFields}
+	}
+	if major, err = ParseInt32(dev[0]); err != nil {
+		return nil, &fieldError{"major:minor", err}
+	}
+	if minor, err = ParseInt32(dev[1]); err != nil {
+		return nil, &fieldError{"major:minor", err}
+	}
+
+	m := &MountInfo{
+		MountID:    int(id),
+		ParentID:   int(parent),
+		Major:      int(major),
+		Minor:      int(minor),
+		Root:       UnescapeMountPath(fields[3]),
+		MountPoint: UnescapeMountPath(fields[4]),
+		Options:    parseMountOptions(fields[5]),
+	}
+
+	// Optional fields, terminated by a single hyphen.
+	i := 6
+	for ; i < len(fields) && fields[i] != "-"; i++ {
+		tag := strings.SplitN(fields[i], ":", 2)
+		var group int64
+		if len(tag) == 2 {
+			if group, err = ParseInt32(tag[1]); err != nil {
+				return nil, &fieldError{tag[0], err}
+			}
+		}
+		switch tag[0] {
+		case "shared":
+			m.Shared = int(group)
+		case "master":
+			m.Master = int(group)
+		case "propagate_from":
+			m.PropagateFrom = int(group)
+		case "unbindable":
+			m.Unbindable = true
+		}
+	}
+
+	if i == len(fields) {
+		return nil, errNoSeparator
+	}
+	if len(fields)-i < 4 {
+		return nil, ErrTooFewFields
+	}
+
+	m.FSType = fields[i+1]
+	m.Source = UnescapeMountPath(fields[i+2])
+	m.SuperOptions = parseMountOptions(fields[i+3])
+
+	return m, nil
+}
+
+// parseMountOptions splits comma separated options, such as
+// rw,size=1024k,mode=755, into a map. Flags map to an empty string.
+func parseMountOptions(s string) map[string]string {
+	options := make(map[string]string)
+	for _, o := range strings.Split(s, ",") {
+		if o == "" {
+			continue
+		}
+		kv := strings.SplitN(o, "=", 2)
+		if len(kv) == 2 {
+			options[kv[0]] = kv[1]
+		} else {
+			options[kv[0]] = ""
+		}
+	}
+	return options
+}
+
+// UnescapeMountPath decodes the octal escapes, such as \040 for a space, the
+// kernel uses for white space and backslashes in mounts and mountinfo.
+func UnescapeMountPath(s string) string {
+	if strings.IndexByte(s, '\\') < 0 {
+		return s
+	}
+
+	b := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+3 < len(s) && isOctal(s[i+1]) && isOctal(s[i+2]) && isOctal(s[i+3]) {
+			b = append(b, (s[i+1]-'0')<<6|(s[i+2]-'0')<<3|(s[i+3]-'0'))
+			i += 3
+			continue
+		}
+		b = append(b, s[i])
+	}
+	return string(b)
+}
+
+func isOctal(c byte) bool {
+	return c >= '0' && c <= '7'
+}
+
+// FindMount returns the mount containing path, that is the one with the
+// longest mount point which is path or one of its parents. When several mounts
+// share that mount point the last one, which hides the others, is returned.
+// path should be absolute, symlinks are not resolved. FindMount returns nil if
+// no mount contains it.
+func FindMount(mounts []MountInfo, path string) *MountInfo {
+	path = filepath.Clean(path)
+
+	var found *MountInfo
+	for i := range mounts {
+		mp := mounts[i].MountPoint
+		if !pathHasPrefix(path, mp) {
+			continue
+		}
+		if found == nil || len(mp) >= len(found.MountPoint) {
+			found = &mounts[i]
+		}
+	}
+	return found
+}
+
+// pathHasPrefix reports whether dir is path or one of its parents.
+func pathHasPrefix(path, dir string) bool {
+	if dir == "/" {
+		return strings.HasPrefix(path, "/")
+	}
+	return path == dir || strings.HasPrefix(path, dir+"/")
+}
@@ -0,0 +1,117 @@
+package linuxtool
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMountInfo(t *testing.T) {
+
+	mounts, err := ReadMountInfo("proc/mountinfo")
+
+	if err != nil {
+		t.Fatal("mountinfo read fail", err)
+	}
+
+	if len(mounts) != 9 {
+		t.Fatal("unexpected mount count", len(mounts))
+	}
+
+	expected := MountInfo{
+		MountID:      25,
+		ParentID:     21,
+		Major:        8,
+		Minor:        17,
+		Root:         "/",
+		MountPoint:   "/mnt/backup disk",
+		Options:      map[string]string{"rw": "", "noatime": ""},
+		Shared:       30,
+		Master:       5,
+		FSType:       "xfs",
+		Source:       "/dev/sdb1",
+		SuperOptions: map[string]string{"rw": "", "attr2": "", "inode64": "", "noquota": ""},
+	}
+
+	if !reflect.DeepEqual(mounts[4], expected) {
+		t.Errorf("unexpected mount %+v", mounts[4])
+	}
+
+	if m := mounts[6]; m.Master != 2 || m.PropagateFrom != 1 || m.Shared != 0 || m.SuperOptions["uid"] != "1000" {
+		t.Errorf("unexpected mount %+v", m)
+	}
+
+	if !mounts[7].Unbindable {
+		t.Error("expect unbindable mount")
+	}
+
+	if m := mounts[8]; m.MountPoint != "/srv/tab\tdir\\x" || m.Source != "user@host:/srv share" || m.FSType != "fuse.sshfs" {
+		t.Errorf("unexpected mount %+v", m)
+	}
+}
+
+func TestProcessMountInfo(t *testing.T) {
+
+	mounts, err := NewProcFS("proc").MountInfo(3323)
+
+	if err != nil {
+		t.Fatal("mountinfo read fail", err)
+	}
+
+	m := mounts[len(mounts)-1]
+
+	if m.Root != "/var/sites/weneed2/webroot" || m.MountPoint != "/ftp/weneed/webroot" || m.Major != 202 || m.Source != "/dev/root" {
+		t.Errorf("unexpected mount %+v", m)
+	}
+
+	if m.SuperOptions["errors"] != "remount-ro" {
+		t.Error("unexpected super options", m.SuperOptions)
+	}
+}
+
+func TestFindMount(t *testing.T) {
+
+	mounts, err := ReadMountInfo("proc/mountinfo")
+
+	if err != nil {
+		t.Fatal("mountinfo read fail", err)
+	}
+
+	tests := map[string]string{
+		"/":                   "/",
+		"/etc/passwd":         "/",
+		"/run/user/1000/bus":  "/run/user/1000",
+		"/run/user/10000":     "/run",
+		"/mnt/backup disk/a":  "/mnt/backup disk",
+		"/mnt/backup":         "/",
+		"/home/../proc/stat/": "/proc",
+	}
+
+	for path, mountPoint := range tests {
+		m := FindMount(mounts, path)
+		if m == nil || m.MountPoint != mountPoint {
+			t.Errorf("unexpected mount of %s: %+v", path, m)
+		}
+	}
+
+	if m := FindMount(nil, "/"); m != nil {
+		t.Error("unexpected mount", m)
+	}
+}
+
+func TestUnescapeMountPath(t *testing.T) {
+
+	tests := map[string]string{
+		`/mnt/a\040b`: "/mnt/a b",
+		`/mnt/a\134b`: `/mnt/a\b`,
+		`/mnt/a\012`:  "/mnt/a\n",
+		`/mnt/a\04`:   `/mnt/a\04`,
+		`/mnt/a\999`:  `/mnt/a\999`,
+		`/mnt/plain`:  "/mnt/plain",
+	}
+
+	for s, expected := range tests {
+		if u := UnescapeMountPath(s); u != expected {
+			t.Errorf("unexpected unescape of %s: %q", s, u)
+		}
+	}
+}
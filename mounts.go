@@ -21,21 +21,41 @@ const (
 	DefaultBufferSize = 1024
 )
 
+// ReadMounts reads and parses the mounts file. Octal escapes in the device and
+// the mount point are decoded, see UnescapeMountPath.
 func ReadMounts(path string) (*Mounts, error) {
+	return readMounts(path, nil)
+}
+
+func readMounts(path string, onError ParseErrorHandler) (*Mounts, error) {
 	fin, err := os.Open(path)
 	if err != nil {
 		return nil, err
 	}
 	defer fin.Close()
 
+	p := newParser(path, onError)
+
 	var mounts = Mounts{}
 
+	n := 0
 	scanner := bufio.NewScanner(fin)
 	for scanner.Scan() {
-		fields := strings.Fields(scanner.Text())
+		n++
+		line := scanner.Text()
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 4 {
+			if err = p.fail(n, "", line, ErrTooFewFields); err != nil {
+				return nil, err
+			}
+			continue
+		}
 		var mount = &Mount{
-			fields[0],
-			fields[1],
+			UnescapeMountPath(fields[0]),
+			UnescapeMountPath(fields[1]),
 			fields[2],
 			fields[3],
 		}
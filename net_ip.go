@@ -24,16 +24,19 @@ type NetSocket struct {
 	Uid                  uint32 `json:"uid"`
 	Inode                uint64 `json:"inode"`
 	SocketReferenceCount uint64 `json:"ref"`
+
+	// Processes holding the socket, set by SocketResolver.
+	Owners []SocketOwner `json:"owners,omitempty"`
 }
 
 func parseNetSocket(f []string, ip NetIPDecoder) (*NetSocket, error) {
 
 	if len(f) < 11 {
-		return nil, errors.New("Cannot parse net socket line: " + strings.Join(f, " "))
+		return nil, ErrTooFewFields
 	}
 
 	if !strings.Contains(f[4], ":") {
-		return nil, errors.New("Cannot parse tx/rx queues: " + f[4])
+		return nil, &fieldError{"tx_queue", errors.New("Cannot parse tx/rx queues: " + f[4])}
 	}
 
 	q := strings.Split(f[4], ":")
@@ -45,35 +48,35 @@ func parseNetSocket(f []string, ip NetIPDecoder) (*NetSocket, error) {
 	var err error // parse error
 
 	if socket.LocalAddress, err = ip(f[1]); err != nil {
-		return nil, err
+		return nil, &fieldError{"local_address", err}
 	}
 
 	if socket.RemoteAddress, err = ip(f[2]); err != nil {
-		return nil, err
+		return nil, &fieldError{"remote_address", err}
 	}
 
 	if s, err = strconv.ParseUint(f[3], 16, 8); err != nil {
-		return nil, err
+		return nil, &fieldError{"st", err}
 	}
 
 	if socket.TxQueue, err = ParseHexUint(q[0]); err != nil {
-		return nil, err
+		return nil, &fieldError{"tx_queue", err}
 	}
 
 	if socket.RxQueue, err = ParseHexUint(q[1]); err != nil {
-		return nil, err
+		return nil, &fieldError{"rx_queue", err}
 	}
 
 	if u, err = ParseUint32(f[7]); err != nil {
-		return nil, err
+		return nil, &fieldError{"uid", err}
 	}
 
 	if socket.Inode, err = ParseUint(f[9]); err != nil {
-		return nil, err
+		return nil, &fieldError{"inode", err}
 	}
 
 	if socket.SocketReferenceCount, err = ParseUint(f[10]); err != nil {
-		return nil, err
+		return nil, &fieldError{"ref", err}
 	}
 
 	socket.Status = uint8(s)
@@ -21,6 +21,10 @@ type NetTCPSocket struct {
 }
 
 func ReadNetTCPSockets(path string, ip NetIPDecoder) (*NetTCPSockets, error) {
+	return readNetTCPSockets(path, ip, nil)
+}
+
+func readNetTCPSockets(path string, ip NetIPDecoder, onError ParseErrorHandler) (*NetTCPSockets, error) {
 
 	b, err := ioutil.ReadFile(path)
 
@@ -28,6 +32,8 @@ func ReadNetTCPSockets(path string, ip NetIPDecoder) (*NetTCPSockets, error) {
 		return nil, err
 	}
 
+	p := newParser(path, onError)
+
 	lines := strings.Split(string(b), "\n")
 
 	tcp := &NetTCPSockets{}
@@ -38,45 +44,70 @@ func ReadNetTCPSockets(path string, ip NetIPDecoder) (*NetTCPSockets, error) {
 
 		f := strings.Fields(line)
 
-		if len(f) < 17 {
+		if len(f) == 0 {
 			continue
 		}
 
-		s, err := parseNetSocket(f, ip)
+		e, err := parseNetTCPSocket(f, ip)
 
 		if err != nil {
-			return nil, err
+			if err = p.fail(i+1, "", line, err); err != nil {
+				return nil, err
+			}
+			continue
 		}
 
-		var n int64
-		e := &NetTCPSocket{
-			NetSocket: *s,
-		}
+		tcp.Sockets = append(tcp.Sockets, *e)
+	}
 
-		if e.RetransmitTimeout, err = ParseUint(f[12]); err != nil {
-			return nil, err
-		}
+	return tcp, nil
+}
 
-		if e.PredictedTick, err = ParseUint(f[13]); err != nil {
-			return nil, err
-		}
+// parseNetTCPSocket parses a line of /proc/net/tcp. The lines of TIME_WAIT
+// and SYN_RECV sockets end after the socket pointer, without the TCP fields,
+// and have a Uid and Inode of 0.
+func parseNetTCPSocket(f []string, ip NetIPDecoder) (*NetTCPSocket, error) {
 
-		if n, err = strconv.ParseInt(f[14], 10, 8); err != nil {
-			return nil, err
-		}
-		e.AckQuick = uint8(n >> 1)
-		e.AckPingpong = (n & 1) == 1
+	if len(f) < 12 {
+		return nil, ErrTooFewFields
+	}
 
-		if e.SendingCongestionWindow, err = ParseUint(f[15]); err != nil {
-			return nil, err
-		}
+	s, err := parseNetSocket(f, ip)
 
-		if e.SlowStartSizeThreshold, err = ParseInt32(f[16]); err != nil {
-			return nil, err
-		}
+	if err != nil {
+		return nil, err
+	}
 
-		tcp.Sockets = append(tcp.Sockets, *e)
+	var n int64
+	e := &NetTCPSocket{
+		NetSocket: *s,
 	}
 
-	return tcp, nil
+	if len(f) < 17 {
+		return e, nil
+	}
+
+	if e.RetransmitTimeout, err = ParseUint(f[12]); err != nil {
+		return nil, &fieldError{"retransmit_timeout", err}
+	}
+
+	if e.PredictedTick, err = ParseUint(f[13]); err != nil {
+		return nil, &fieldError{"predicted_tick", err}
+	}
+
+	if n, err = strconv.ParseInt(f[14], 10, 8); err != nil {
+		return nil, &fieldError{"ack_quick", err}
+	}
+	e.AckQuick = uint8(n >> 1)
+	e.AckPingpong = (n & 1) == 1
+
+	if e.SendingCongestionWindow, err = ParseUint(f[15]); err != nil {
+		return nil, &fieldError{"sending_congestion_window", err}
+	}
+
+	if e.SlowStartSizeThreshold, err = ParseInt32(f[16]); err != nil {
+		return nil, &fieldError{"slow_start_size_threshold", err}
+	}
+
+	return e, nil
 }
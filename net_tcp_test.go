@@ -58,6 +58,29 @@ func TestReadNetTCP(t *testing.T) {
 
 }
 
+func TestReadNetTCPTimeWait(t *testing.T) {
+
+	tcp, err := ReadNetTCPSockets("proc/net_tcp_time_wait", NetIPv4Decoder)
+
+	if err != nil {
+		t.Fatal("net tcp read fail", err)
+	}
+
+	if len(tcp.Sockets) != 3 {
+		t.Fatalf("unexpected sockets %+v", tcp.Sockets)
+	}
+
+	// SYN_RECV, then TIME_WAIT, without the TCP fields.
+	expected := []NetTCPSocket{
+		{NetSocket: NetSocket{LocalAddress: "96.126.103.155:80", RemoteAddress: "114.249.233.91:56156", Status: 3, SocketReferenceCount: 2}},
+		{NetSocket: NetSocket{LocalAddress: "127.0.0.1:8080", RemoteAddress: "127.0.0.1:34048", Status: 6, SocketReferenceCount: 3}},
+	}
+
+	if !reflect.DeepEqual(tcp.Sockets[1:], expected) {
+		t.Errorf("unexpected sockets %+v", tcp.Sockets[1:])
+	}
+}
+
 func TestReadNetTCP6(t *testing.T) {
 
 	tcp, err := ReadNetTCPSockets("proc/net_tcp6", NetIPv6Decoder)
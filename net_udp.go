@@ -15,6 +15,10 @@ type NetUDPSocket struct {
 }
 
 func ReadNetUDPSockets(path string, ip NetIPDecoder) (*NetUDPSockets, error) {
+	return readNetUDPSockets(path, ip, nil)
+}
+
+func readNetUDPSockets(path string, ip NetIPDecoder, onError ParseErrorHandler) (*NetUDPSockets, error) {
 
 	b, err := ioutil.ReadFile(path)
 
@@ -22,6 +26,8 @@ func ReadNetUDPSockets(path string, ip NetIPDecoder) (*NetUDPSockets, error) {
 		return nil, err
 	}
 
+	p := newParser(path, onError)
+
 	lines := strings.Split(string(b), "\n")
 
 	udp := &NetUDPSockets{}
@@ -32,23 +38,17 @@ func ReadNetUDPSockets(path string, ip NetIPDecoder) (*NetUDPSockets, error) {
 
 		f := strings.Fields(line)
 
-		if len(f) < 13 {
+		if len(f) == 0 {
 			continue
 		}
 
-		s, err := parseNetSocket(f, ip)
+		e, err := parseNetUDPSocket(f, ip)
 
 		if err != nil {
-			return nil, err
-		}
-
-		e := &NetUDPSocket{
-			NetSocket: *s,
-			Drops:     0,
-		}
-
-		if e.Drops, err = ParseUint(f[12]); err != nil {
-			return nil, err
+			if err = p.fail(i+1, "", line, err); err != nil {
+				return nil, err
+			}
+			continue
 		}
 
 		udp.Sockets = append(udp.Sockets, *e)
@@ -56,3 +56,27 @@ func ReadNetUDPSockets(path string, ip NetIPDecoder) (*NetUDPSockets, error) {
 
 	return udp, nil
 }
+
+func parseNetUDPSocket(f []string, ip NetIPDecoder) (*NetUDPSocket, error) {
+
+	if len(f) < 13 {
+		return nil, ErrTooFewFields
+	}
+
+	s, err := parseNetSocket(f, ip)
+
+	if err != nil {
+		return nil, err
+	}
+
+	e := &NetUDPSocket{
+		NetSocket: *s,
+		Drops:     0,
+	}
+
+	if e.Drops, err = ParseUint(f[12]); err != nil {
+		return nil, &fieldError{"drops", err}
+	}
+
+	return e, nil
+}
@@ -133,6 +133,10 @@ type NetStat struct {
 }
 
 func ReadNetStat(path string) (*NetStat, error) {
+	return readNetStat(path, nil)
+}
+
+func readNetStat(path string, onError ParseErrorHandler) (*NetStat, error) {
 	data, err := ioutil.ReadFile(path)
 
 	if err != nil {
@@ -142,19 +146,10 @@ func ReadNetStat(path string) (*NetStat, error) {
 	lines := strings.Split(string(data), "\n")
 
 	// Maps a netstat metric to its value (i.e. SyncookiesSent --> 0)
-	statMap := make(map[string]string)
-
-	// patterns
-	// TcpExt: SyncookiesSent SyncookiesRecv SyncookiesFailed... <-- header
-	// TcpExt: 0 0 1764... <-- values
-
-	for i := 1; i < len(lines); i = i + 2 {
-		headers := strings.Fields(lines[i-1][strings.Index(lines[i-1], ":")+1:])
-		values := strings.Fields(lines[i][strings.Index(lines[i], ":")+1:])
+	statMap, err := parseHeaderValueLines(newParser(path, onError), lines, false)
 
-		for j, header := range headers {
-			statMap[header] = values[j]
-		}
+	if err != nil {
+		return nil, err
 	}
 
 	var netstat NetStat = NetStat{}
@@ -164,8 +159,7 @@ func ReadNetStat(path string) (*NetStat, error) {
 
 	for i := 0; i < elem.NumField(); i++ {
 		if val, ok := statMap[typeOfElem.Field(i).Name]; ok {
-			parsedVal, _ := ParseUint(val)
-			elem.Field(i).SetUint(parsedVal)
+			elem.Field(i).SetUint(val)
 		}
 	}
 
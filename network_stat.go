@@ -25,47 +25,88 @@ type NetworkStat struct {
 	TxCompressed uint64 `json:"txcompressed"`
 }
 
+var networkStatFields = []string{
+	"rxbytes", "rxpackets", "rxerrs", "rxdrop", "rxfifo", "rxframe", "rxcompressed", "rxmulticast",
+	"txbytes", "txpackets", "txerrs", "txdrop", "txfifo", "txcolls", "txcarrier", "txcompressed",
+}
+
 func ReadNetworkStat(path string) ([]NetworkStat, error) {
+	return readNetworkStat(path, nil)
+}
+
+func readNetworkStat(path string, onError ParseErrorHandler) ([]NetworkStat, error) {
 	data, err := ioutil.ReadFile(path)
 
 	if err != nil {
 		return nil, err
 	}
 
+	p := newParser(path, onError)
+
 	lines := strings.Split(string(data), "\n")
 
-	// lines[2:] remove /proc/net/dev header
-	results := make([]NetworkStat, len(lines[2:])-1)
+	results := make([]NetworkStat, 0, len(lines))
+
+	for i, line := range lines {
+		// lines[:2] are the /proc/net/dev header
+		if i < 2 {
+			continue
+		}
 
-	for i, line := range lines[2:] {
 		// patterns
 		// <iface>: 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0
 		// or
 		// <iface>:0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 (without space after colon)
 		colon := strings.Index(line, ":")
 
-		if colon > 0 {
-			metrics := line[colon+1:]
-			fields := strings.Fields(metrics)
-
-			results[i].Iface = strings.Replace(line[0:colon], " ", "", -1)
-			results[i].RxBytes = ParseUint64(fields[0])
-			results[i].RxPackets = ParseUint64(fields[1])
-			results[i].RxErrs = ParseUint64(fields[2])
-			results[i].RxDrop = ParseUint64(fields[3])
-			results[i].RxFifo = ParseUint64(fields[4])
-			results[i].RxFrame = ParseUint64(fields[5])
-			results[i].RxCompressed = ParseUint64(fields[6])
-			results[i].RxMulticast = ParseUint64(fields[7])
-			results[i].TxBytes = ParseUint64(fields[8])
-			results[i].TxPackets = ParseUint64(fields[9])
-			results[i].TxErrs = ParseUint64(fields[10])
-			results[i].TxDrop = ParseUint64(fields[11])
-			results[i].TxFifo = ParseUint64(fields[12])
-			results[i].TxColls = ParseUint64(fields[13])
-			results[i].TxCarrier = ParseUint64(fields[14])
-			results[i].TxCompressed = ParseUint64(fields[15])
+		if colon <= 0 {
+			continue
+		}
+
+		fields := strings.Fields(line[colon+1:])
+
+		if len(fields) < len(networkStatFields) {
+			if err = p.fail(i+1, "", line, ErrTooFewFields); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		var n [16]uint64
+
+		for j := range n {
+			if n[j], err = ParseUint(fields[j]); err != nil {
+				err = &fieldError{networkStatFields[j], err}
+				break
+			}
+		}
+
+		if err != nil {
+			if err = p.fail(i+1, "", line, err); err != nil {
+				return nil, err
+			}
+			continue
 		}
+
+		results = append(results, NetworkStat{
+			Iface:        strings.Replace(line[0:colon], " ", "", -1),
+			RxBytes:      n[0],
+			RxPackets:    n[1],
+			RxErrs:       n[2],
+			RxDrop:       n[3],
+			RxFifo:       n[4],
+			RxFrame:      n[5],
+			RxCompressed: n[6],
+			RxMulticast:  n[7],
+			TxBytes:      n[8],
+			TxPackets:    n[9],
+			TxErrs:       n[10],
+			TxDrop:       n[11],
+			TxFifo:       n[12],
+			TxColls:      n[13],
+			TxCarrier:    n[14],
+			TxCompressed: n[15],
+		})
 	}
 
 	return results, nil
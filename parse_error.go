@@ -0,0 +1,109 @@
+package linuxtool
+
+import (
+	"errors"
+	"strconv"
+)
+
+// ErrTooFewFields is the underlying error of a ParseError for a line which is
+// truncated or has fewer fields than the file format requires.
+var ErrTooFewFields = errors.New("too few fields")
+
+// ParseError describes content of a proc file which could not be parsed, for
+// example a truncated read or a line written by an unexpected kernel variant.
+type ParseError struct {
+	File  string `json:"file"`  // path of the file being read
+	Line  int    `json:"line"`  // 1-based line number, 0 if not known
+	Field string `json:"field"` // name of the field being parsed, if any
+	Text  string `json:"text"`  // raw text which could not be parsed
+	Err   error  `json:"-"`     // underlying error
+}
+
+func (e *ParseError) Error() string {
+
+	s := "cannot parse " + e.File
+
+	if e.Line > 0 {
+		s += " line " + strconv.Itoa(e.Line)
+	}
+
+	if e.Field != "" {
+		s += " field " + e.Field
+	}
+
+	s += ": " + strconv.Quote(e.Text)
+
+	if e.Err != nil {
+		s += ": " + e.Err.Error()
+	}
+
+	return s
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// ParseErrorHandler decides what a reader does with content it cannot parse.
+// Returning nil skips the offending line (or leaves the field unset) and keeps
+// reading; returning an error aborts the read with that error.
+//
+// The Read* functions abort on the first ParseError. A handler is set through
+// ProcFS.OnParseError.
+type ParseErrorHandler func(*ParseError) error
+
+// SkipParseErrors is a ParseErrorHandler which skips everything it is given.
+func SkipParseErrors(*ParseError) error {
+	return nil
+}
+
+// AbortOnParseError is a ParseErrorHandler which aborts on the first error.
+func AbortOnParseError(e *ParseError) error {
+	return e
+}
+
+// fieldError tags an error with the name of the field it occurred in, so that
+// helpers parsing several fields of a line can report which one failed.
+type fieldError struct {
+	field string
+	err   error
+}
+
+func (e *fieldError) Error() string {
+	return e.field + ": " + e.err.Error()
+}
+
+// parser reports ParseErrors of one file to a ParseErrorHandler.
+type parser struct {
+	file    string
+	onError ParseErrorHandler
+}
+
+func newParser(file string, onError ParseErrorHandler) *parser {
+
+	if onError == nil {
+		onError = AbortOnParseError
+	}
+
+	return &parser{file: file, onError: onError}
+}
+
+// fail reports a ParseError and returns the handler's verdict: nil to skip,
+// an error to abort.
+func (p *parser) fail(line int, field string, text string, err error) error {
+
+	if fe, ok := err.(*fieldError); ok {
+		if field == "" {
+			field = fe.field
+		}
+		err = fe.err
+	}
+
+	return p.onError(&ParseError{
+		File:  p.file,
+		Line:  line,
+		Field: field,
+		Text:  text,
+		Err:   err,
+	})
+}
@@ -0,0 +1,91 @@
+package linuxtool
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseErrorAbort(t *testing.T) {
+
+	_, err := ReadDiskStats("proc/diskstats_truncated")
+
+	pe, ok := err.(*ParseError)
+
+	if !ok {
+		t.Fatal("expected a *ParseError", err)
+	}
+
+	expected := &ParseError{
+		File: "proc/diskstats_truncated",
+		Line: 2,
+		Text: "   8       1 sda1 408408656 130632009 4314825866",
+		Err:  ErrTooFewFields,
+	}
+
+	if !reflect.DeepEqual(pe, expected) {
+		t.Errorf("not equal to expected %+v", expected)
+	}
+
+	t.Log(pe)
+
+	_, err = ReadMemInfo("proc/meminfo_malformed")
+
+	if pe, ok = err.(*ParseError); !ok {
+		t.Fatal("expected a *ParseError", err)
+	}
+
+	if pe.Line != 2 || pe.Field != "MemFree" || pe.Err != ErrTooFewFields {
+		t.Errorf("unexpected parse error %+v", pe)
+	}
+
+	_, err = ReadMounts("proc/mounts_truncated")
+
+	if pe, ok = err.(*ParseError); !ok || pe.Line != 2 {
+		t.Fatal("expected a *ParseError on line 2", err)
+	}
+
+	_, err = ReadUptime("proc/uptime_truncated")
+
+	if _, ok = err.(*ParseError); !ok {
+		t.Fatal("expected a *ParseError", err)
+	}
+}
+
+func TestParseErrorSkip(t *testing.T) {
+
+	stats, err := readDiskStats("proc/diskstats_truncated", SkipParseErrors)
+
+	if err != nil {
+		t.Fatal("disk stat read fail", err)
+	}
+
+	if len(stats) != 2 || stats[0].Name != "sda" || stats[1].Name != "sdb" {
+		t.Errorf("unexpected disk stats %+v", stats)
+	}
+
+	var errs []*ParseError
+
+	info, err := readMemInfo("proc/meminfo_malformed", func(e *ParseError) error {
+		errs = append(errs, e)
+		return nil
+	})
+
+	if err != nil {
+		t.Fatal("meminfo read fail", err)
+	}
+
+	if info.MemTotal != 1011048 || info.Buffers != 44304 {
+		t.Errorf("unexpected meminfo %+v", info)
+	}
+
+	if len(errs) != 2 || errs[0].Field != "MemFree" || errs[1].Field != "Cached" || errs[1].Line != 4 {
+		t.Errorf("unexpected parse errors %+v", errs)
+	}
+
+	fs := NewProcFS("proc")
+	fs.OnParseError = SkipParseErrors
+
+	if _, err := fs.Stat(); err != nil {
+		t.Fatal("stat read fail", err)
+	}
+}
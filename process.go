@@ -1,62 +1,200 @@
 package linuxtool
 
 import (
+	"errors"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
+	"syscall"
 )
 
+// ErrProcessGone is returned when a process exits before or while it is read.
+var ErrProcessGone = errors.New("process gone")
+
+// ProcessParts selects the files of /proc/<pid> read by ReadProcessParts.
+type ProcessParts uint
+
+const (
+	ProcessStatPart ProcessParts = 1 << iota
+	ProcessStatmPart
+	ProcessStatusPart
+	ProcessIOPart
+	ProcessCmdlinePart
+	ProcessCommPart
+	ProcessEnvironPart
+
+	// DefaultProcessParts are the parts read by ReadProcess.
+	DefaultProcessParts = ProcessStatPart | ProcessStatmPart | ProcessStatusPart | ProcessIOPart | ProcessCmdlinePart
+
+	AllProcessParts = DefaultProcessParts | ProcessCommPart | ProcessEnvironPart
+)
+
+var processPartNames = []string{"stat", "statm", "status", "io", "cmdline", "comm", "environ"}
+
+// Has reports whether all of parts are set.
+func (p ProcessParts) Has(parts ProcessParts) bool {
+	return p&parts == parts
+}
+
+// String returns the file names of the parts, comma separated.
+func (p ProcessParts) String() string {
+
+	names := make([]string, 0, len(processPartNames))
+
+	for i, name := range processPartNames {
+		if p&(1<<uint(i)) != 0 {
+			names = append(names, name)
+		}
+	}
+
+	return strings.Join(names, ",")
+}
+
 type Process struct {
-	Status  ProcessStatus `json:"status"`
-	Statm   ProcessStatm  `json:"statm"`
-	Stat    ProcessStat   `json:"stat"`
-	IO      ProcessIO     `json:"io"`
-	Cmdline string        `json:"cmdline"`
+	Status  ProcessStatus     `json:"status"`
+	Statm   ProcessStatm      `json:"statm"`
+	Stat    ProcessStat       `json:"stat"`
+	IO      ProcessIO         `json:"io"`
+	Cmdline string            `json:"cmdline"` // arguments joined by spaces, for display
+	Args    []string          `json:"args"`
+	Comm    string            `json:"comm"`
+	Environ map[string]string `json:"environ"`
+
+	// Parts which were requested but could not be read for lack of
+	// permission, e.g. io of processes of other users; they are left empty.
+	Unavailable ProcessParts `json:"unavailable"`
 }
 
+// ReadProcess reads the DefaultProcessParts of the process pid from the proc
+// directory at path, see ReadProcessParts.
 func ReadProcess(pid uint64, path string) (*Process, error) {
+	return readProcess(pid, path, DefaultProcessParts, nil)
+}
+
+// ReadProcessParts reads the parts of the process pid from the proc directory
+// at path.
+//
+// Parts the caller is not permitted to read are flagged in Unavailable rather
+// than failing the call. ErrProcessGone is returned if the process does not
+// exist or exits while it is read.
+func ReadProcessParts(pid uint64, path string, parts ProcessParts) (*Process, error) {
+	return readProcess(pid, path, parts, nil)
+}
+
+func readProcess(pid uint64, path string, parts ProcessParts, onError ParseErrorHandler) (*Process, error) {
 
 	var err error
 
 	p := filepath.Join(path, strconv.FormatUint(pid, 10))
 
 	if _, err = os.Stat(p); err != nil {
-		return nil, err
+		return nil, processError(err)
 	}
 
 	process := Process{}
 
-	var io *ProcessIO
-	var stat *ProcessStat
-	var statm *ProcessStatm
-	var status *ProcessStatus
-	var cmdline string
+	// read calls fn for a requested part and sorts out its error.
+	read := func(part ProcessParts, fn func() error) error {
+
+		if !parts.Has(part) {
+			return nil
+		}
+
+		err := fn()
+
+		if os.IsPermission(err) {
+			process.Unavailable |= part
+			return nil
+		}
+
+		return processError(err)
+	}
 
-	if io, err = ReadProcessIO(filepath.Join(p, "io")); err != nil {
+	if err = read(ProcessIOPart, func() error {
+		io, err := readProcessIO(filepath.Join(p, "io"), onError)
+		if err == nil {
+			process.IO = *io
+		}
+		return err
+	}); err != nil {
 		return nil, err
 	}
 
-	if stat, err = ReadProcessStat(filepath.Join(p, "stat")); err != nil {
+	if err = read(ProcessStatPart, func() error {
+		stat, err := readProcessStat(filepath.Join(p, "stat"), onError)
+		if err == nil {
+			process.Stat = *stat
+		}
+		return err
+	}); err != nil {
 		return nil, err
 	}
 
-	if statm, err = ReadProcessStatm(filepath.Join(p, "statm")); err != nil {
+	if err = read(ProcessStatmPart, func() error {
+		statm, err := readProcessStatm(filepath.Join(p, "statm"), onError)
+		if err == nil {
+			process.Statm = *statm
+		}
+		return err
+	}); err != nil {
 		return nil, err
 	}
 
-	if status, err = ReadProcessStatus(filepath.Join(p, "status")); err != nil {
+	if err = read(ProcessStatusPart, func() error {
+		status, err := readProcessStatus(filepath.Join(p, "status"), onError)
+		if err == nil {
+			process.Status = *status
+		}
+		return err
+	}); err != nil {
 		return nil, err
 	}
 
-	if cmdline, err = ReadProcessCmdline(filepath.Join(p, "cmdline")); err != nil {
+	if err = read(ProcessCmdlinePart, func() error {
+		b, err := ioutil.ReadFile(filepath.Join(p, "cmdline"))
+		if err == nil {
+			process.Cmdline = parseProcessCmdline(b)
+			process.Args = parseProcessArgv(b)
+		}
+		return err
+	}); err != nil {
 		return nil, err
 	}
 
-	process.IO = *io
-	process.Stat = *stat
-	process.Statm = *statm
-	process.Status = *status
-	process.Cmdline = cmdline
+	if err = read(ProcessCommPart, func() (err error) {
+		process.Comm, err = ReadProcessComm(filepath.Join(p, "comm"))
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	if err = read(ProcessEnvironPart, func() (err error) {
+		process.Environ, err = ReadProcessEnviron(filepath.Join(p, "environ"))
+		return err
+	}); err != nil {
+		return nil, err
+	}
 
 	return &process, nil
 }
+
+// processError maps the errors of a process which exited to ErrProcessGone:
+// its directory is gone, or its files fail with ESRCH once it is reaped.
+func processError(err error) error {
+
+	if err == nil {
+		return nil
+	}
+
+	if os.IsNotExist(err) {
+		return ErrProcessGone
+	}
+
+	if pe, ok := err.(*os.PathError); ok && pe.Err == syscall.ESRCH {
+		return ErrProcessGone
+	}
+
+	return err
+}
@@ -1,10 +1,13 @@
 package linuxtool
 
 import (
+	"bytes"
 	"io/ioutil"
 	"strings"
 )
 
+// ReadProcessCmdline reads the command line of a process, with its arguments
+// joined by spaces for display. See ReadProcessArgv for the arguments.
 func ReadProcessCmdline(path string) (string, error) {
 
 	b, err := ioutil.ReadFile(path)
@@ -13,6 +16,11 @@ func ReadProcessCmdline(path string) (string, error) {
 		return "", err
 	}
 
+	return parseProcessCmdline(b), nil
+}
+
+func parseProcessCmdline(b []byte) string {
+
 	l := len(b) - 1 // Define limit before last byte ('\0')
 	z := byte(0)    // '\0' or null byte
 	s := byte(0x20) // space byte
@@ -35,5 +43,43 @@ func ReadProcessCmdline(path string) (string, error) {
 
 	x := strings.TrimSpace(string(b[0:c]))
 
-	return x, nil
+	return x
+}
+
+// ReadProcessArgv reads the arguments of a process from its cmdline file, as
+// they were passed to execve. Empty arguments are kept, a trailing one too.
+//
+// Processes which rewrite their argv, such as proftpd or postgres, write their
+// title over the first argument and pad the rest with NUL bytes. A first
+// argument followed only by several NUL bytes is taken for such a title and
+// returned alone, so a command line of one argument and empty ones is
+// misread. A command line of empty arguments only is returned as such; the
+// cmdline of kernel threads and zombies is empty, which gives no arguments.
+func ReadProcessArgv(path string) ([]string, error) {
+
+	b, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return parseProcessArgv(b), nil
+}
+
+func parseProcessArgv(b []byte) []string {
+
+	if len(b) == 0 {
+		return []string{}
+	}
+
+	// A rewritten title padded with NUL bytes.
+	if i := bytes.IndexByte(b, 0); i > 0 && len(b)-i > 1 && len(bytes.TrimRight(b, "\x00")) == i {
+		return []string{string(b[:i])}
+	}
+
+	// The last argument is terminated by a NUL byte, unless the process
+	// rewrote it.
+	b = bytes.TrimSuffix(b, []byte{0})
+
+	return strings.Split(string(b), "\x00")
 }
@@ -1,6 +1,7 @@
 package linuxtool
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -37,3 +38,61 @@ func TestReadProcessCmdlineComplex(t *testing.T) {
 
 	t.Logf("%+v", cmdline)
 }
+
+func TestReadProcessArgv(t *testing.T) {
+
+	args, err := ReadProcessArgv("proc/5811/cmdline")
+
+	if err != nil {
+		t.Fatal("process argv read fail", err)
+	}
+
+	expected := []string{
+		"/home/c9s/.config/sublime-text-2/Packages/User/GoSublime/linux-x64/bin/gosublime.margo_r14.12.06-1_go1.4.2.exe",
+		"-oom", "1000", "-poll", "30", "-tag", "r14.12.06-1",
+	}
+
+	if !reflect.DeepEqual(args, expected) {
+		t.Error("unexpected args", args)
+	}
+
+	// An empty last argument.
+	args, err = ReadProcessArgv("proc/cmdline_empty_arg")
+
+	if err != nil {
+		t.Fatal("process argv read fail", err)
+	}
+
+	if expected = []string{"git", "commit", "-m", ""}; !reflect.DeepEqual(args, expected) {
+		t.Errorf("unexpected args %q", args)
+	}
+
+	// A title padded by setproctitle.
+	args, err = ReadProcessArgv("proc/3323/cmdline")
+
+	if err != nil {
+		t.Fatal("process argv read fail", err)
+	}
+
+	if expected = []string{"proftpd: (accepting connections)"}; !reflect.DeepEqual(args, expected) {
+		t.Errorf("unexpected args %q", args)
+	}
+
+	tests := map[string][]string{
+		"":                         {},
+		"\x00":                     {""},
+		"\x00\x00":                 {"", ""},
+		"sh\x00-c\x00echo a b\x00": {"sh", "-c", "echo a b"},
+		"printf\x00\x00x\x00":      {"printf", "", "x"},
+		"printf\x00x\x00\x00":      {"printf", "x", ""},
+		"\x00x\x00":                {"", "x"},
+		"title\x00\x00\x00":        {"title"},
+		"no trailing nul":          {"no trailing nul"},
+	}
+
+	for cmdline, expected := range tests {
+		if args := parseProcessArgv([]byte(cmdline)); !reflect.DeepEqual(args, expected) {
+			t.Errorf("unexpected args of %q: %q", cmdline, args)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package linuxtool
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+)
+
+// ReadProcessEnviron reads the environment a process was started with from
+// its environ file. Changes the process made to its environment later are not
+// reflected.
+//
+// A variable defined several times keeps its first value, as getenv(3) does;
+// an entry without '=' maps to an empty value.
+func ReadProcessEnviron(path string) (map[string]string, error) {
+
+	b, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	environ := make(map[string]string)
+
+	for _, e := range bytes.Split(b, []byte{0}) {
+
+		if len(e) == 0 {
+			continue
+		}
+
+		kv := strings.SplitN(string(e), "=", 2)
+
+		if _, ok := environ[kv[0]]; ok {
+			continue
+		}
+
+		if len(kv) == 2 {
+			environ[kv[0]] = kv[1]
+		} else {
+			environ[kv[0]] = ""
+		}
+	}
+
+	return environ, nil
+}
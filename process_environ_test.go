@@ -0,0 +1,40 @@
+package linuxtool
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReadProcessEnviron(t *testing.T) {
+
+	environ, err := ReadProcessEnviron("proc/5811/environ")
+
+	if err != nil {
+		t.Fatal("process environ read fail", err)
+	}
+
+	expected := map[string]string{
+		"HOME":       "/home/c9s",
+		"PATH":       "/usr/local/bin:/usr/bin:/bin",
+		"GOPATH":     "/home/c9s/go",
+		"LANG":       "en_US.UTF-8",
+		"APP_CONFIG": "/etc/app/config.yml",
+		"EMPTY":      "",
+		"OPTS":       "-Xmx1g -Dname=a=b",
+	}
+
+	if !reflect.DeepEqual(environ, expected) {
+		t.Error("unexpected environ", environ)
+	}
+
+	// Wiped by the process.
+	environ, err = ReadProcessEnviron("proc/3323/environ")
+
+	if err != nil {
+		t.Fatal("process environ read fail", err)
+	}
+
+	if len(environ) != 0 {
+		t.Error("unexpected environ", environ)
+	}
+}
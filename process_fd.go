@@ -0,0 +1,371 @@
+package linuxtool
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// FDType classifies a ProcessFD by what it refers to.
+type FDType string
+
+const (
+	FDTypeFile      FDType = "file"
+	FDTypeDevice    FDType = "device" // a file under /dev, e.g. /dev/null or a tty
+	FDTypeMemfd     FDType = "memfd"  // memfd_create(2) memory, always marked deleted
+	FDTypeShm       FDType = "shm"    // POSIX shared memory under /dev/shm, or a System V segment
+	FDTypeSocket    FDType = "socket"
+	FDTypePipe      FDType = "pipe"
+	FDTypeAnonInode FDType = "anon_inode" // e.g. eventfd, eventpoll, timerfd, inotify
+	FDTypeOther     FDType = "other"      // other pseudo files, e.g. net:[4026531840]
+)
+
+// ProcessFD is an open file descriptor of a process, from /proc/<pid>/fd and
+// /proc/<pid>/fdinfo.
+type ProcessFD struct {
+	FD      uint64 `json:"fd"`
+	Target  string `json:"target"` // the fd link, e.g. /var/log/syslog or socket:[4635]
+	Type    FDType `json:"type"`
+	Inode   uint64 `json:"inode"`   // of sockets and pipes, from the target, otherwise from fdinfo
+	Deleted bool   `json:"deleted"` // the file was deleted since it was opened
+	Pos     uint64 `json:"pos"`
+	Flags   uint64 `json:"flags"` // open(2) flags
+	MntID   uint64 `json:"mnt_id"`
+}
+
+// Path returns the path of the file the fd refers to, without the deleted
+// marker, or "" if it is not a file.
+func (fd *ProcessFD) Path() string {
+
+	if fd.Type != FDTypeFile && fd.Type != FDTypeDevice && fd.Type != FDTypeShm {
+		return ""
+	}
+
+	return strings.TrimSuffix(fd.Target, deletedSuffix)
+}
+
+// AccessMode returns "r", "w" or "rw", as lsof reports it.
+func (fd *ProcessFD) AccessMode() string {
+
+	switch fd.Flags & syscall.O_ACCMODE {
+	case syscall.O_WRONLY:
+		return "w"
+	case syscall.O_RDWR:
+		return "rw"
+	}
+
+	return "r"
+}
+
+// parseFDTarget classifies the target of an fd link and returns the inode of
+// "<type>:[<inode>]" targets.
+func parseFDTarget(target string) (FDType, uint64, bool) {
+
+	if strings.HasPrefix(target, "/") {
+
+		deleted := strings.HasSuffix(target, deletedSuffix)
+
+		switch {
+		case strings.HasPrefix(target, "/memfd:"):
+			return FDTypeMemfd, 0, deleted
+		case strings.HasPrefix(target, "/dev/shm/"), strings.HasPrefix(target, "/SYSV"):
+			return FDTypeShm, 0, deleted
+		case strings.HasPrefix(target, "/dev/"):
+			return FDTypeDevice, 0, deleted
+		}
+
+		return FDTypeFile, 0, deleted
+	}
+
+	if strings.HasPrefix(target, "anon_inode:") {
+		return FDTypeAnonInode, 0, false
+	}
+
+	t := FDTypeOther
+
+	switch {
+	case strings.HasPrefix(target, "socket:"):
+		t = FDTypeSocket
+	case strings.HasPrefix(target, "pipe:"):
+		t = FDTypePipe
+	}
+
+	var inode uint64
+
+	if i := strings.Index(target, ":["); i >= 0 && strings.HasSuffix(target, "]") {
+		inode, _ = ParseUint(target[i+2 : len(target)-1])
+	}
+
+	return t, inode, false
+}
+
+// readProcessFDInfo reads the position, flags, mount and inode of an fdinfo
+// file into fd. Lines specific to the fd type, e.g. tfd of eventpoll, are
+// skipped.
+func readProcessFDInfo(path string, fd *ProcessFD, onError ParseErrorHandler) error {
+
+	b, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return err
+	}
+
+	p := newParser(path, onError)
+
+	for n, line := range strings.Split(string(b), "\n") {
+
+		l := strings.SplitN(line, ":", 2)
+
+		if len(l) != 2 {
+			continue
+		}
+
+		k := l[0]
+		v := strings.TrimSpace(l[1])
+
+		switch k {
+		case "pos":
+			fd.Pos, err = ParseUint(v)
+		case "flags":
+			fd.Flags, err = strconv.ParseUint(v, 8, 64)
+		case "mnt_id":
+			fd.MntID, err = ParseUint(v)
+		case "ino":
+			if fd.Inode == 0 {
+				fd.Inode, err = ParseUint(v)
+			}
+		}
+
+		if err != nil {
+			if err = p.fail(n+1, k, line, err); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// ReadProcessFDs lists the open file descriptors of the process pid from the
+// proc directory at path, in fd order. Fds closed while they are read are
+// left out. Reading the fds of processes of other users requires privileges.
+func ReadProcessFDs(pid uint64, path string) ([]ProcessFD, error) {
+	return readProcessFDs(pid, path, nil)
+}
+
+func readProcessFDs(pid uint64, path string, onError ParseErrorHandler) ([]ProcessFD, error) {
+
+	p := filepath.Join(path, strconv.FormatUint(pid, 10))
+
+	var fds []ProcessFD
+
+	err := walkProcessFDs(p, os.Readlink, func(n uint64, target string) error {
+
+		fd := ProcessFD{FD: n, Target: target}
+
+		fd.Type, fd.Inode, fd.Deleted = parseFDTarget(target)
+
+		// fdinfo is missing on kernels older than 2.6.22.
+		err := readProcessFDInfo(filepath.Join(p, "fdinfo", strconv.FormatUint(n, 10)), &fd, onError)
+
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		fds = append(fds, fd)
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, processError(err)
+	}
+
+	sort.Slice(fds, func(i, j int) bool { return fds[i].FD < fds[j].FD })
+
+	return fds, nil
+}
+
+// walkProcessFDs calls fn with the number and link target of each open fd of
+// the process directory dir, in no particular order, reading the links with
+// readlink. Fds closed while they are walked are skipped; other errors are
+// returned as is, for the caller to map with processError.
+func walkProcessFDs(dir string, readlink func(string) (string, error), fn func(fd uint64, target string) error) error {
+
+	f, err := os.Open(filepath.Join(dir, "fd"))
+
+	if err != nil {
+		return err
+	}
+
+	names, err := f.Readdirnames(-1)
+
+	f.Close()
+
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+
+		fd, err := ParseUint(name)
+
+		if err != nil {
+			continue
+		}
+
+		target, err := readlink(filepath.Join(dir, "fd", name))
+
+		if os.IsNotExist(err) {
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if err = fn(fd, target); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CountFDTypes returns the number of fds of each type.
+func CountFDTypes(fds []ProcessFD) map[FDType]int {
+
+	counts := make(map[FDType]int)
+
+	for i := range fds {
+		counts[fds[i].Type]++
+	}
+
+	return counts
+}
+
+// DeletedFile is a deleted file still held open by a process, whose space is
+// not freed until it is closed. Memfds and shared memory are not files of a
+// disk and are not reported.
+type DeletedFile struct {
+	Pid  uint64 `json:"pid"`
+	Comm string `json:"comm"`
+	FD   uint64 `json:"fd"`
+	Path string `json:"path"`
+	Pos  uint64 `json:"pos"`
+}
+
+// FindDeletedFiles lists the deleted files held open by the processes of the
+// proc directory at path, in pid and fd order. Processes whose fds cannot be
+// read, because they exited or belong to another user, are skipped.
+func FindDeletedFiles(path string) ([]DeletedFile, error) {
+	return findDeletedFiles(path, nil)
+}
+
+func findDeletedFiles(path string, onError ParseErrorHandler) ([]DeletedFile, error) {
+
+	var deleted []DeletedFile
+
+	err := WalkPID(path, func(pid uint64) error {
+
+		fds, err := readProcessFDs(pid, path, onError)
+
+		if err == ErrProcessGone || os.IsPermission(err) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		var comm string
+
+		for i := range fds {
+
+			if !fds[i].Deleted || fds[i].Type != FDTypeFile {
+				continue
+			}
+
+			if comm == "" {
+				comm, _ = ReadProcessComm(filepath.Join(path, strconv.FormatUint(pid, 10), "comm"))
+			}
+
+			deleted = append(deleted, DeletedFile{
+				Pid:  pid,
+				Comm: comm,
+				FD:   fds[i].FD,
+				Path: fds[i].Path(),
+				Pos:  fds[i].Pos,
+			})
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(deleted, func(i, j int) bool { return deleted[i].Pid < deleted[j].Pid })
+
+	return deleted, nil
+}
+
+// FileNr is the system-wide count of file handles, from
+// /proc/sys/fs/file-nr.
+type FileNr struct {
+	Allocated uint64 `json:"allocated"`
+	Free      uint64 `json:"free"` // always 0 since Linux 2.6
+	Max       uint64 `json:"max"`  // fs.file-max
+}
+
+var fileNrFields = []string{"allocated", "free", "max"}
+
+// Used returns the number of file handles in use.
+func (f *FileNr) Used() uint64 {
+	return satSub(f.Allocated, f.Free)
+}
+
+// ReadFileNr reads and parses the file-nr file, /proc/sys/fs/file-nr.
+func ReadFileNr(path string) (*FileNr, error) {
+	return readFileNr(path, nil)
+}
+
+func readFileNr(path string, onError ParseErrorHandler) (*FileNr, error) {
+
+	b, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	p := newParser(path, onError)
+
+	s := strings.TrimSpace(string(b))
+	f := strings.Fields(s)
+
+	nr := FileNr{}
+
+	if len(f) != len(fileNrFields) {
+		if err = p.fail(1, "", s, ErrTooFewFields); err != nil {
+			return nil, err
+		}
+		return &nr, nil
+	}
+
+	values := []*uint64{&nr.Allocated, &nr.Free, &nr.Max}
+
+	for i := range values {
+		if *values[i], err = ParseUint(f[i]); err != nil {
+			if err = p.fail(1, fileNrFields[i], s, err); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return &nr, nil
+}
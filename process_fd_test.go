@@ -0,0 +1,106 @@
+package linuxtool
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReadProcessFDs(t *testing.T) {
+
+	fds, err := NewProcFS("proc").ProcessFDs(3323)
+
+	if err != nil {
+		t.Fatal("process fds read fail", err)
+	}
+
+	expected := []ProcessFD{
+		{FD: 0, Target: "/dev/null", Type: FDTypeDevice, Pos: 16, Flags: 0100002},
+		{FD: 1, Target: "/dev/null", Type: FDTypeDevice, Flags: 0100002},
+		{FD: 2, Target: "socket:[4635]", Type: FDTypeSocket, Inode: 4635, Flags: 02},
+		{FD: 5, Target: "/etc/passwd", Type: FDTypeFile, Pos: 1028, Flags: 0100000},
+		{FD: 6, Target: "/etc/group", Type: FDTypeFile, Pos: 2167, Flags: 0100000},
+		{FD: 7, Target: "/var/log/proftpd/proftpd.log", Type: FDTypeFile, Pos: 12867, Flags: 0502001},
+		{FD: 8, Target: "/var/log/proftpd/xferlog (deleted)", Type: FDTypeFile, Deleted: true, Flags: 0502001},
+		{FD: 9, Target: "anon_inode:[eventpoll]", Type: FDTypeAnonInode, Inode: 1057, Flags: 02000002, MntID: 15},
+		{FD: 10, Target: "pipe:[9021]", Type: FDTypePipe, Inode: 9021, Flags: 04000, MntID: 14},
+	}
+
+	if !reflect.DeepEqual(fds, expected) {
+		t.Errorf("unexpected fds %+v", fds)
+	}
+
+	if fds[0].AccessMode() != "rw" || fds[3].AccessMode() != "r" || fds[6].AccessMode() != "w" {
+		t.Error("unexpected access modes", fds[0].AccessMode(), fds[3].AccessMode(), fds[6].AccessMode())
+	}
+
+	if fds[6].Path() != "/var/log/proftpd/xferlog" || fds[2].Path() != "" {
+		t.Error("unexpected paths", fds[6].Path(), fds[2].Path())
+	}
+
+	counts := map[FDType]int{FDTypeDevice: 2, FDTypeSocket: 1, FDTypeFile: 4, FDTypeAnonInode: 1, FDTypePipe: 1}
+
+	if c := CountFDTypes(fds); !reflect.DeepEqual(c, counts) {
+		t.Error("unexpected counts", c)
+	}
+
+	if _, err = ReadProcessFDs(99999, "proc"); err != ErrProcessGone {
+		t.Error("expected process gone", err)
+	}
+}
+
+func TestParseFDTarget(t *testing.T) {
+
+	cases := []struct {
+		target  string
+		t       FDType
+		inode   uint64
+		deleted bool
+	}{
+		{"/memfd:journal (deleted)", FDTypeMemfd, 0, true},
+		{"/dev/shm/pulse-shm-1201 (deleted)", FDTypeShm, 0, true},
+		{"/SYSV00000000 (deleted)", FDTypeShm, 0, true},
+		{"/var/log/old.log (deleted)", FDTypeFile, 0, true},
+		{"/dev/pts/0", FDTypeDevice, 0, false},
+		{"anon_inode:[eventfd]", FDTypeAnonInode, 0, false},
+		{"anon_inode:inotify", FDTypeAnonInode, 0, false},
+		{"net:[4026531840]", FDTypeOther, 4026531840, false},
+	}
+
+	for _, c := range cases {
+		if typ, inode, deleted := parseFDTarget(c.target); typ != c.t || inode != c.inode || deleted != c.deleted {
+			t.Error("unexpected classification of", c.target, typ, inode, deleted)
+		}
+	}
+}
+
+func TestFindDeletedFiles(t *testing.T) {
+
+	deleted, err := FindDeletedFiles("proc")
+
+	if err != nil {
+		t.Fatal("deleted files read fail", err)
+	}
+
+	// The deleted memfd and shared memory of 4854 are left out.
+	expected := []DeletedFile{
+		{Pid: 3323, Comm: "proftpd", FD: 8, Path: "/var/log/proftpd/xferlog"},
+		{Pid: 4854, Comm: "(sd-pam)", FD: 6, Path: "/var/log/old.log", Pos: 4096},
+	}
+
+	if !reflect.DeepEqual(deleted, expected) {
+		t.Errorf("unexpected deleted files %+v", deleted)
+	}
+}
+
+func TestReadFileNr(t *testing.T) {
+
+	nr, err := ReadFileNr("proc/sys_fs_file-nr")
+
+	if err != nil {
+		t.Fatal("file-nr read fail", err)
+	}
+
+	if *nr != (FileNr{Allocated: 2944, Max: 98029}) || nr.Used() != 2944 {
+		t.Errorf("unexpected file-nr %+v", nr)
+	}
+}
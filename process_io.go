@@ -18,6 +18,10 @@ type ProcessIO struct {
 }
 
 func ReadProcessIO(path string) (*ProcessIO, error) {
+	return readProcessIO(path, nil)
+}
+
+func readProcessIO(path string, onError ParseErrorHandler) (*ProcessIO, error) {
 
 	b, err := ioutil.ReadFile(path)
 
@@ -25,6 +29,8 @@ func ReadProcessIO(path string) (*ProcessIO, error) {
 		return nil, err
 	}
 
+	p := newParser(path, onError)
+
 	// Maps a io metric to its value (i.e. rchar --> 100000)
 	m := map[string]uint64{}
 
@@ -32,7 +38,7 @@ func ReadProcessIO(path string) (*ProcessIO, error) {
 
 	lines := strings.Split(string(b), "\n")
 
-	for _, line := range lines {
+	for i, line := range lines {
 
 		if !strings.Contains(line, ": ") {
 			continue
@@ -44,7 +50,10 @@ func ReadProcessIO(path string) (*ProcessIO, error) {
 		v, err := ParseUint(l[1])
 
 		if err != nil {
-			return nil, err
+			if err = p.fail(i+1, k, line, err); err != nil {
+				return nil, err
+			}
+			continue
 		}
 
 		m[k] = v
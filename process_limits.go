@@ -0,0 +1,276 @@
+package linuxtool
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// RLimitInfinity is the value of an unlimited ProcessLimit.
+const RLimitInfinity = ^uint64(0)
+
+// ProcessLimit is a resource limit of a process, see getrlimit(2).
+type ProcessLimit struct {
+	Soft  uint64 `json:"soft"` // RLimitInfinity if unlimited
+	Hard  uint64 `json:"hard"` // RLimitInfinity if unlimited
+	Units string `json:"units"`
+}
+
+// Unlimited reports whether the soft limit, the one enforced, is unlimited.
+func (l ProcessLimit) Unlimited() bool {
+	return l.Soft == RLimitInfinity
+}
+
+// ProcessLimits are the resource limits of a process, from /proc/<pid>/limits.
+type ProcessLimits struct {
+	CPUTime          ProcessLimit `json:"cpu_time" field:"Max cpu time"`
+	FileSize         ProcessLimit `json:"file_size" field:"Max file size"`
+	DataSize         ProcessLimit `json:"data_size" field:"Max data size"`
+	StackSize        ProcessLimit `json:"stack_size" field:"Max stack size"`
+	CoreFileSize     ProcessLimit `json:"core_file_size" field:"Max core file size"`
+	ResidentSet      ProcessLimit `json:"resident_set" field:"Max resident set"`
+	Processes        ProcessLimit `json:"processes" field:"Max processes"`
+	OpenFiles        ProcessLimit `json:"open_files" field:"Max open files"`
+	LockedMemory     ProcessLimit `json:"locked_memory" field:"Max locked memory"`
+	AddressSpace     ProcessLimit `json:"address_space" field:"Max address space"`
+	FileLocks        ProcessLimit `json:"file_locks" field:"Max file locks"`
+	PendingSignals   ProcessLimit `json:"pending_signals" field:"Max pending signals"`
+	MsgqueueSize     ProcessLimit `json:"msgqueue_size" field:"Max msgqueue size"`
+	NicePriority     ProcessLimit `json:"nice_priority" field:"Max nice priority"`
+	RealtimePriority ProcessLimit `json:"realtime_priority" field:"Max realtime priority"`
+	RealtimeTimeout  ProcessLimit `json:"realtime_timeout" field:"Max realtime timeout"`
+}
+
+// processLimitFields maps the names of the limits to the index of their field.
+var processLimitFields = func() map[string]int {
+
+	t := reflect.TypeOf(ProcessLimits{})
+	m := make(map[string]int, t.NumField())
+
+	for i := 0; i < t.NumField(); i++ {
+		m[t.Field(i).Tag.Get("field")] = i
+	}
+
+	return m
+}()
+
+// ReadProcessLimits reads and parses the limits file of a process.
+func ReadProcessLimits(path string) (*ProcessLimits, error) {
+	return readProcessLimits(path, nil)
+}
+
+func readProcessLimits(path string, onError ParseErrorHandler) (*ProcessLimits, error) {
+
+	b, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	p := newParser(path, onError)
+
+	limits := ProcessLimits{}
+
+	lines := strings.Split(string(b), "\n")
+
+	// The table is aligned on its header; limit names contain spaces, so the
+	// columns are cut at the offsets of the header titles.
+	soft := strings.Index(lines[0], "Soft Limit")
+	hard := strings.Index(lines[0], "Hard Limit")
+	units := strings.Index(lines[0], "Units")
+
+	if soft < 0 || hard < soft || units < hard {
+		if err = p.fail(1, "", lines[0], ErrTooFewFields); err != nil {
+			return nil, err
+		}
+		return &limits, nil
+	}
+
+	v := reflect.ValueOf(&limits).Elem()
+
+	for i, line := range lines[1:] {
+
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		if len(line) < units {
+			line += strings.Repeat(" ", units-len(line))
+		}
+
+		name := strings.TrimSpace(line[:soft])
+
+		field, ok := processLimitFields[name]
+
+		if !ok {
+			continue
+		}
+
+		l := ProcessLimit{Units: strings.TrimSpace(line[units:])}
+
+		if l.Soft, err = parseProcessLimit(line[soft:hard]); err != nil {
+			if err = p.fail(i+2, name, line, err); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if l.Hard, err = parseProcessLimit(line[hard:units]); err != nil {
+			if err = p.fail(i+2, name, line, err); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		v.Field(field).Set(reflect.ValueOf(l))
+	}
+
+	return &limits, nil
+}
+
+func parseProcessLimit(s string) (uint64, error) {
+
+	s = strings.TrimSpace(s)
+
+	if s == "unlimited" {
+		return RLimitInfinity, nil
+	}
+
+	return ParseUint(s)
+}
+
+// LimitHeadroom is the usage of a resource against its soft limit.
+type LimitHeadroom struct {
+	Resource  string  `json:"resource"` // json name of the ProcessLimits field
+	Used      uint64  `json:"used"`
+	Limit     uint64  `json:"limit"`     // RLimitInfinity if unlimited
+	Available uint64  `json:"available"` // RLimitInfinity if unlimited
+	Percent   float64 `json:"percent"`   // used of limit, 0 if unlimited
+
+	// The usage could not be read for lack of permission, e.g. the open fds
+	// of processes of other users; Used, Available and Percent are 0.
+	Unavailable bool `json:"unavailable"`
+}
+
+func newLimitHeadroom(resource string, used uint64, limit ProcessLimit) LimitHeadroom {
+
+	h := LimitHeadroom{Resource: resource, Used: used, Limit: limit.Soft, Available: RLimitInfinity}
+
+	if limit.Unlimited() {
+		return h
+	}
+
+	h.Available = satSub(limit.Soft, used)
+
+	if limit.Soft > 0 {
+		h.Percent = float64(used) * 100 / float64(limit.Soft)
+	}
+
+	return h
+}
+
+// ProcessHeadroom is how close a process is to its resource limits.
+//
+// Note:
+// * Max processes applies to all the tasks of the real user; Threads only
+//   counts the threads of the process, which is a lower bound.
+// * Max resident set is not enforced since Linux 2.6.
+type ProcessHeadroom struct {
+	Pid          uint64        `json:"pid"`
+	OpenFiles    LimitHeadroom `json:"open_files"`    // open fds vs Max open files
+	Threads      LimitHeadroom `json:"threads"`       // threads vs Max processes
+	AddressSpace LimitHeadroom `json:"address_space"` // VmSize vs Max address space, bytes
+	ResidentSet  LimitHeadroom `json:"resident_set"`  // VmRSS vs Max resident set, bytes
+}
+
+// Headrooms returns the headrooms in a slice, for reports.
+func (h *ProcessHeadroom) Headrooms() []LimitHeadroom {
+	return []LimitHeadroom{h.OpenFiles, h.Threads, h.AddressSpace, h.ResidentSet}
+}
+
+// Exceeds returns the headrooms whose usage is at least percent of the limit,
+// e.g. 80 to alert before "too many open files". Unavailable headrooms are
+// left out.
+func (h *ProcessHeadroom) Exceeds(percent float64) []LimitHeadroom {
+
+	var exceeded []LimitHeadroom
+
+	for _, r := range h.Headrooms() {
+		if !r.Unavailable && r.Limit != RLimitInfinity && r.Percent >= percent {
+			exceeded = append(exceeded, r)
+		}
+	}
+
+	return exceeded
+}
+
+// CalculateProcessHeadroom compares the limits of a process with its usage,
+// its count of open fds and its status.
+func CalculateProcessHeadroom(limits *ProcessLimits, status *ProcessStatus, fds uint64) *ProcessHeadroom {
+	return &ProcessHeadroom{
+		Pid:          status.Pid,
+		OpenFiles:    newLimitHeadroom("open_files", fds, limits.OpenFiles),
+		Threads:      newLimitHeadroom("processes", status.Threads, limits.Processes),
+		AddressSpace: newLimitHeadroom("address_space", status.VmSize*1024, limits.AddressSpace),
+		ResidentSet:  newLimitHeadroom("resident_set", status.VmRSS*1024, limits.ResidentSet),
+	}
+}
+
+// CountProcessFDs returns the number of open file descriptors of the process
+// pid, from the proc directory at path.
+func CountProcessFDs(pid uint64, path string) (uint64, error) {
+
+	f, err := os.Open(filepath.Join(path, strconv.FormatUint(pid, 10), "fd"))
+
+	if err != nil {
+		return 0, err
+	}
+
+	defer f.Close()
+
+	names, err := f.Readdirnames(-1)
+
+	return uint64(len(names)), err
+}
+
+// ReadProcessHeadroom reads the limits, status and open fds of the process pid
+// from the proc directory at path and compares them. Reading the fds of
+// processes of other users requires privileges; without them OpenFiles is
+// flagged Unavailable and the other headrooms are still returned.
+func ReadProcessHeadroom(pid uint64, path string) (*ProcessHeadroom, error) {
+	return readProcessHeadroom(pid, path, nil, CountProcessFDs)
+}
+
+func readProcessHeadroom(pid uint64, path string, onError ParseErrorHandler, countFDs func(uint64, string) (uint64, error)) (*ProcessHeadroom, error) {
+
+	p := filepath.Join(path, strconv.FormatUint(pid, 10))
+
+	limits, err := readProcessLimits(filepath.Join(p, "limits"), onError)
+
+	if err != nil {
+		return nil, processError(err)
+	}
+
+	status, err := readProcessStatus(filepath.Join(p, "status"), onError)
+
+	if err != nil {
+		return nil, processError(err)
+	}
+
+	fds, err := countFDs(pid, path)
+
+	if os.IsPermission(err) {
+		h := CalculateProcessHeadroom(limits, status, 0)
+		h.OpenFiles = LimitHeadroom{Resource: "open_files", Limit: limits.OpenFiles.Soft, Unavailable: true}
+		return h, nil
+	}
+
+	if err != nil {
+		return nil, processError(err)
+	}
+
+	return CalculateProcessHeadroom(limits, status, fds), nil
+}
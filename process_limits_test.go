@@ -0,0 +1,113 @@
+package linuxtool
+
+import (
+	"os"
+	"reflect"
+	"syscall"
+	"testing"
+)
+
+func TestReadProcessLimits(t *testing.T) {
+
+	limits, err := ReadProcessLimits("proc/3323/limits")
+
+	if err != nil {
+		t.Fatal("process limits read fail", err)
+	}
+
+	expected := &ProcessLimits{
+		CPUTime:          ProcessLimit{RLimitInfinity, RLimitInfinity, "seconds"},
+		FileSize:         ProcessLimit{RLimitInfinity, RLimitInfinity, "bytes"},
+		DataSize:         ProcessLimit{RLimitInfinity, RLimitInfinity, "bytes"},
+		StackSize:        ProcessLimit{8388608, RLimitInfinity, "bytes"},
+		CoreFileSize:     ProcessLimit{0, 0, "bytes"},
+		ResidentSet:      ProcessLimit{RLimitInfinity, RLimitInfinity, "bytes"},
+		Processes:        ProcessLimit{12091, 12091, "processes"},
+		OpenFiles:        ProcessLimit{1024, 1024, "files"},
+		LockedMemory:     ProcessLimit{65536, 65536, "bytes"},
+		AddressSpace:     ProcessLimit{RLimitInfinity, RLimitInfinity, "bytes"},
+		FileLocks:        ProcessLimit{RLimitInfinity, RLimitInfinity, "locks"},
+		PendingSignals:   ProcessLimit{12091, 12091, "signals"},
+		MsgqueueSize:     ProcessLimit{819200, 819200, "bytes"},
+		NicePriority:     ProcessLimit{0, 0, ""},
+		RealtimePriority: ProcessLimit{0, 0, ""},
+		RealtimeTimeout:  ProcessLimit{RLimitInfinity, RLimitInfinity, "us"},
+	}
+
+	if !reflect.DeepEqual(limits, expected) {
+		t.Errorf("unexpected limits %+v", limits)
+	}
+}
+
+func TestReadProcessLimitsMalformed(t *testing.T) {
+
+	path := "proc/limits_malformed"
+
+	_, err := ReadProcessLimits(path)
+
+	if err == nil {
+		t.Fatal("expected parse error")
+	}
+
+	if pe, ok := err.(*ParseError); !ok || pe.Line != 2 || pe.Field != "Max open files" {
+		t.Errorf("unexpected error %#v", err)
+	}
+
+	limits, err := readProcessLimits(path, SkipParseErrors)
+
+	if err != nil {
+		t.Fatal("process limits read fail", err)
+	}
+
+	if limits.OpenFiles != (ProcessLimit{}) || limits.Processes != (ProcessLimit{100, 200, "processes"}) {
+		t.Errorf("unexpected limits %+v", limits)
+	}
+}
+
+func TestReadProcessHeadroom(t *testing.T) {
+
+	h, err := NewProcFS("proc").ProcessHeadroom(3323)
+
+	if err != nil {
+		t.Fatal("process headroom read fail", err)
+	}
+
+	expected := &ProcessHeadroom{
+		Pid:          3323,
+		OpenFiles:    LimitHeadroom{"open_files", 9, 1024, 1015, 9 * 100.0 / 1024, false},
+		Threads:      LimitHeadroom{"processes", 1, 12091, 12090, 100.0 / 12091, false},
+		AddressSpace: LimitHeadroom{"address_space", 16212 * 1024, RLimitInfinity, RLimitInfinity, 0, false},
+		ResidentSet:  LimitHeadroom{"resident_set", 2088 * 1024, RLimitInfinity, RLimitInfinity, 0, false},
+	}
+
+	if !reflect.DeepEqual(h, expected) {
+		t.Errorf("unexpected headroom %+v", h)
+	}
+
+	if exceeded := h.Exceeds(0.5); len(exceeded) != 1 || exceeded[0].Resource != "open_files" {
+		t.Error("unexpected exceeded limits", exceeded)
+	}
+
+	if _, err = ReadProcessHeadroom(99999, "proc"); err != ErrProcessGone {
+		t.Error("expected process gone", err)
+	}
+
+	// The fds of a process of another user.
+	denied := func(pid uint64, path string) (uint64, error) {
+		return 0, &os.PathError{Op: "open", Path: path, Err: syscall.EACCES}
+	}
+
+	h, err = readProcessHeadroom(3323, "proc", nil, denied)
+
+	if err != nil {
+		t.Fatal("process headroom read fail", err)
+	}
+
+	if !h.OpenFiles.Unavailable || h.OpenFiles.Limit != 1024 || h.OpenFiles.Used != 0 || h.Threads != expected.Threads {
+		t.Errorf("unexpected headroom %+v", h)
+	}
+
+	if exceeded := h.Exceeds(0); len(exceeded) != 1 || exceeded[0].Resource != "processes" {
+		t.Error("unexpected exceeded limits", exceeded)
+	}
+}
@@ -0,0 +1,332 @@
+package linuxtool
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MapKind classifies a ProcessMap by what backs it.
+type MapKind string
+
+const (
+	MapKindHeap      MapKind = "heap"
+	MapKindStack     MapKind = "stack"
+	MapKindVDSO      MapKind = "vdso"      // [vdso], [vvar] and [vsyscall]
+	MapKindAnonymous MapKind = "anonymous" // anonymous memory, e.g. mmap or thread stacks
+	MapKindFile      MapKind = "file"
+	MapKindDeleted   MapKind = "deleted" // file deleted or replaced since it was mapped
+	MapKindShared    MapKind = "shm"     // System V or POSIX shared memory, memfd
+)
+
+// deletedSuffix is appended to the pathname of a mapped file which was
+// deleted, or replaced by a package upgrade.
+const deletedSuffix = " (deleted)"
+
+// ProcessMap is a memory mapping of a process, a line of /proc/<pid>/maps and
+// the header of each mapping of /proc/<pid>/smaps.
+type ProcessMap struct {
+	StartAddr uint64 `json:"start_addr"`
+	EndAddr   uint64 `json:"end_addr"`
+	Perms     string `json:"perms"` // e.g. r-xp, p for private and s for shared
+	Offset    uint64 `json:"offset"`
+	Major     int    `json:"major"`
+	Minor     int    `json:"minor"`
+	Inode     uint64 `json:"inode"`
+	Pathname  string `json:"pathname"` // file, [heap], [stack] or empty for anonymous mappings
+}
+
+var processMapFields = []string{"address", "perms", "offset", "dev", "inode", "pathname"}
+
+// parseProcessMap parses the header of a mapping, e.g.
+// 08048000-080f6000 r-xp 00000000 ca:00 809        /usr/sbin/proftpd
+func parseProcessMap(line string) (*ProcessMap, error) {
+
+	// The pathname may contain spaces, split the 5 fields before it only.
+	fields := make([]string, 0, 6)
+	rest := line
+
+	for len(fields) < 5 {
+
+		rest = strings.TrimLeft(rest, " ")
+
+		i := strings.IndexByte(rest, ' ')
+
+		if i < 0 {
+			if rest != "" {
+				fields = append(fields, rest)
+				rest = ""
+			}
+			break
+		}
+
+		fields = append(fields, rest[:i])
+		rest = rest[i:]
+	}
+
+	if len(fields) < 5 {
+		return nil, ErrTooFewFields
+	}
+
+	m := &ProcessMap{
+		Perms:    fields[1],
+		Pathname: strings.TrimLeft(rest, " "),
+	}
+
+	var err error
+
+	addr := strings.SplitN(fields[0], "-", 2)
+
+	if len(addr) != 2 {
+		return nil, &fieldError{processMapFields[0], ErrTooFewFields}
+	}
+
+	if m.StartAddr, err = ParseHexUint(addr[0]); err != nil {
+		return nil, &fieldError{processMapFields[0], err}
+	}
+
+	if m.EndAddr, err = ParseHexUint(addr[1]); err != nil {
+		return nil, &fieldError{processMapFields[0], err}
+	}
+
+	if m.Offset, err = ParseHexUint(fields[2]); err != nil {
+		return nil, &fieldError{processMapFields[2], err}
+	}
+
+	dev := strings.SplitN(fields[3], ":", 2)
+
+	if len(dev) != 2 {
+		return nil, &fieldError{processMapFields[3], ErrTooFewFields}
+	}
+
+	var major, minor uint64
+
+	if major, err = strconv.ParseUint(dev[0], 16, 32); err != nil {
+		return nil, &fieldError{processMapFields[3], err}
+	}
+
+	if minor, err = strconv.ParseUint(dev[1], 16, 32); err != nil {
+		return nil, &fieldError{processMapFields[3], err}
+	}
+
+	m.Major = int(major)
+	m.Minor = int(minor)
+
+	if m.Inode, err = ParseUint(fields[4]); err != nil {
+		return nil, &fieldError{processMapFields[4], err}
+	}
+
+	return m, nil
+}
+
+// Size returns the size of the mapping in bytes.
+func (m *ProcessMap) Size() uint64 {
+	return m.EndAddr - m.StartAddr
+}
+
+// ReadProcessMaps reads and parses the maps file of a process.
+func ReadProcessMaps(path string) ([]ProcessMap, error) {
+	return readProcessMaps(path, nil)
+}
+
+func readProcessMaps(path string, onError ParseErrorHandler) ([]ProcessMap, error) {
+
+	b, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	p := newParser(path, onError)
+
+	lines := strings.Split(string(b), "\n")
+
+	maps := make([]ProcessMap, 0, len(lines))
+
+	for i, line := range lines {
+
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		m, err := parseProcessMap(line)
+
+		if err != nil {
+			if err = p.fail(i+1, "", line, err); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		maps = append(maps, *m)
+	}
+
+	return maps, nil
+}
+
+// Kind classifies the mapping by its pathname.
+func (m *ProcessMap) Kind() MapKind {
+
+	switch {
+	case m.Pathname == "":
+		return MapKindAnonymous
+	case m.Pathname == "[heap]":
+		return MapKindHeap
+	case m.Pathname == "[stack]" || strings.HasPrefix(m.Pathname, "[stack:"):
+		return MapKindStack
+	case m.Pathname == "[vdso]" || m.Pathname == "[vvar]" || m.Pathname == "[vsyscall]":
+		return MapKindVDSO
+	case strings.HasPrefix(m.Pathname, "["):
+		// e.g. [anon:name] of named anonymous mappings
+		return MapKindAnonymous
+	// Shared memory segments always read as deleted.
+	case strings.HasPrefix(m.Pathname, "/SYSV"), strings.HasPrefix(m.Pathname, "/dev/shm/"),
+		strings.HasPrefix(m.Pathname, "/memfd:"):
+		return MapKindShared
+	case strings.HasSuffix(m.Pathname, deletedSuffix):
+		return MapKindDeleted
+	}
+
+	return MapKindFile
+}
+
+// File returns the path of the mapped file, without the deleted marker, or ""
+// if the mapping is not backed by a file.
+func (m *ProcessMap) File() string {
+
+	switch m.Kind() {
+	case MapKindFile, MapKindDeleted, MapKindShared:
+		return strings.TrimSuffix(m.Pathname, deletedSuffix)
+	}
+
+	return ""
+}
+
+// IsSharedLibrary reports whether the mapped file is a shared library, that is
+// its name ends with .so or contains .so. followed by a version.
+func (m *ProcessMap) IsSharedLibrary() bool {
+
+	name := filepath.Base(m.File())
+
+	return strings.HasSuffix(name, ".so") || strings.Contains(name, ".so.")
+}
+
+// MappedLibrary is the memory a process maps from one shared library.
+type MappedLibrary struct {
+	Path    string `json:"path"`
+	Size    uint64 `json:"size"`    // bytes of address space, of all its mappings
+	Deleted bool   `json:"deleted"` // the file was replaced, e.g. by an upgrade
+}
+
+// MappedLibraries groups the shared library mappings of maps by library, in
+// path order.
+func MappedLibraries(maps []ProcessMap) []MappedLibrary {
+
+	byPath := make(map[string]*MappedLibrary)
+
+	for i := range maps {
+
+		m := &maps[i]
+
+		if !m.IsSharedLibrary() {
+			continue
+		}
+
+		path := m.File()
+
+		lib, ok := byPath[path]
+
+		if !ok {
+			lib = &MappedLibrary{Path: path}
+			byPath[path] = lib
+		}
+
+		lib.Size += m.Size()
+		lib.Deleted = lib.Deleted || m.Kind() == MapKindDeleted
+	}
+
+	libs := make([]MappedLibrary, 0, len(byPath))
+
+	for _, lib := range byPath {
+		libs = append(libs, *lib)
+	}
+
+	sort.Slice(libs, func(i, j int) bool { return libs[i].Path < libs[j].Path })
+
+	return libs
+}
+
+// DeletedLibraries returns the paths of the shared libraries mapped in maps
+// which were deleted since, in path order.
+func DeletedLibraries(maps []ProcessMap) []string {
+
+	var deleted []string
+
+	for _, lib := range MappedLibraries(maps) {
+		if lib.Deleted {
+			deleted = append(deleted, lib.Path)
+		}
+	}
+
+	return deleted
+}
+
+// StaleProcess is a process still mapping shared libraries deleted since it
+// started, typically replaced by an upgrade, which needs a restart to load
+// the new ones.
+type StaleProcess struct {
+	Pid       uint64   `json:"pid"`
+	Comm      string   `json:"comm"`
+	Libraries []string `json:"libraries"`
+}
+
+// FindStaleProcesses reads the maps of all processes of the proc directory at
+// path and returns the ones mapping deleted shared libraries, in pid order.
+// Processes whose maps cannot be read, because they exited or belong to
+// another user, are skipped.
+func FindStaleProcesses(path string) ([]StaleProcess, error) {
+	return findStaleProcesses(path, nil)
+}
+
+func findStaleProcesses(path string, onError ParseErrorHandler) ([]StaleProcess, error) {
+
+	var stale []StaleProcess
+
+	err := WalkPID(path, func(pid uint64) error {
+
+		dir := filepath.Join(path, strconv.FormatUint(pid, 10))
+
+		maps, err := readProcessMaps(filepath.Join(dir, "maps"), onError)
+
+		if processError(err) == ErrProcessGone || os.IsPermission(err) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		libs := DeletedLibraries(maps)
+
+		if len(libs) == 0 {
+			return nil
+		}
+
+		comm, _ := ReadProcessComm(filepath.Join(dir, "comm"))
+
+		stale = append(stale, StaleProcess{Pid: pid, Comm: comm, Libraries: libs})
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(stale, func(i, j int) bool { return stale[i].Pid < stale[j].Pid })
+
+	return stale, nil
+}
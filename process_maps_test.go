@@ -0,0 +1,103 @@
+package linuxtool
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReadProcessMaps(t *testing.T) {
+
+	maps, err := ReadProcessMaps("proc/3323/maps")
+
+	if err != nil {
+		t.Fatal("process maps read fail", err)
+	}
+
+	if len(maps) != 178 {
+		t.Fatal("unexpected mapping count", len(maps))
+	}
+
+	expected := ProcessMap{
+		StartAddr: 0xb6924000,
+		EndAddr:   0xb692e000,
+		Perms:     "r-xp",
+		Major:     0xca,
+		Inode:     261804,
+		Pathname:  "/lib/i386-linux-gnu/libnss_nis-2.15.so",
+	}
+
+	if !reflect.DeepEqual(maps[5], expected) {
+		t.Errorf("unexpected mapping %+v", maps[5])
+	}
+
+	if maps[3].Kind() != MapKindAnonymous || maps[4].Kind() != MapKindHeap || maps[5].Kind() != MapKindFile {
+		t.Error("unexpected kinds", maps[3].Kind(), maps[4].Kind(), maps[5].Kind())
+	}
+
+	if libs := DeletedLibraries(maps); len(libs) != 0 {
+		t.Error("unexpected deleted libraries", libs)
+	}
+
+	for _, lib := range MappedLibraries(maps) {
+		if lib.Path == "/lib/i386-linux-gnu/libnss_nis-2.15.so" && lib.Size != 0xc000 {
+			t.Errorf("unexpected library %+v", lib)
+		}
+		if lib.Path == "/usr/sbin/proftpd" || lib.Path == "/usr/lib/locale/locale-archive" {
+			t.Error("not a library", lib.Path)
+		}
+	}
+}
+
+func TestProcessMapKind(t *testing.T) {
+
+	maps, err := NewProcFS("proc").ProcessMaps(4854)
+
+	if err != nil {
+		t.Fatal("process maps read fail", err)
+	}
+
+	expected := []MapKind{
+		MapKindFile, MapKindFile, MapKindHeap, MapKindShared,
+		MapKindDeleted, MapKindDeleted, MapKindDeleted, MapKindDeleted,
+		MapKindAnonymous, MapKindFile, MapKindFile, MapKindShared, MapKindShared,
+		MapKindDeleted, MapKindStack, MapKindVDSO, MapKindVDSO, MapKindVDSO,
+	}
+
+	if len(maps) != len(expected) {
+		t.Fatal("unexpected mapping count", len(maps))
+	}
+
+	for i := range maps {
+		if k := maps[i].Kind(); k != expected[i] {
+			t.Error("unexpected kind of", maps[i].Pathname, k)
+		}
+	}
+
+	libs := MappedLibraries(maps)
+
+	expectedLibs := []MappedLibrary{
+		{Path: "/usr/lib/x86_64-linux-gnu/libc.so.6", Size: 0x219000, Deleted: true},
+		{Path: "/usr/lib/x86_64-linux-gnu/libpam.so.0.85.1", Size: 0x9000},
+	}
+
+	if !reflect.DeepEqual(libs, expectedLibs) {
+		t.Errorf("unexpected libraries %+v", libs)
+	}
+}
+
+func TestFindStaleProcesses(t *testing.T) {
+
+	stale, err := FindStaleProcesses("proc")
+
+	if err != nil {
+		t.Fatal("stale processes read fail", err)
+	}
+
+	expected := []StaleProcess{
+		{Pid: 4854, Comm: "(sd-pam)", Libraries: []string{"/usr/lib/x86_64-linux-gnu/libc.so.6"}},
+	}
+
+	if !reflect.DeepEqual(stale, expected) {
+		t.Errorf("unexpected stale processes %+v", stale)
+	}
+}
@@ -1,14 +1,29 @@
 package linuxtool
 
 import (
+	"bufio"
+	"errors"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 )
 
+// ErrStopWalk can be returned by a WalkPID callback to stop the walk early
+// without WalkPID reporting an error.
+var ErrStopWalk = errors.New("stop walk")
+
+// Number of directory entries read per getdents round trip.
+const pidWalkBatchSize = 256
+
 func ReadMaxPID(path string) (uint64, error) {
+	return readMaxPID(path, nil)
+}
+
+func readMaxPID(path string, onError ParseErrorHandler) (uint64, error) {
 
 	b, err := ioutil.ReadFile(path)
 
@@ -21,34 +36,152 @@ func ReadMaxPID(path string) (uint64, error) {
 	i, err := ParseUint(s)
 
 	if err != nil {
-		return 0, err
+		return 0, newParser(path, onError).fail(1, "pid_max", s, err)
 	}
 
 	return i, nil
 
 }
 
+// WalkPID calls fn for every numeric entry of the proc directory at path, in
+// directory order. The directory is read in batches, so the cost of a walk
+// depends on the number of running processes and not on pid_max.
+//
+// Returning an error from fn stops the walk and WalkPID returns that error,
+// unless it is ErrStopWalk, in which case WalkPID returns nil.
+func WalkPID(path string, fn func(pid uint64) error) error {
+
+	d, err := os.Open(path)
+
+	if err != nil {
+		return err
+	}
+
+	defer d.Close()
+
+	for {
+
+		names, err := d.Readdirnames(pidWalkBatchSize)
+
+		for _, name := range names {
+
+			pid, perr := ParseUint(name)
+
+			if perr != nil {
+				continue
+			}
+
+			if ferr := fn(pid); ferr != nil {
+				if ferr == ErrStopWalk {
+					return nil
+				}
+				return ferr
+			}
+		}
+
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// WalkTGID is like WalkPID but only visits thread-group leaders.
+//
+// Reading a proc directory only lists thread-group leaders, so on one WalkTGID
+// is WalkPID. On a task directory, /proc/<pid>/task, the tids are kept whose
+// status reports a Tgid equal to their own id. Threads exiting before their
+// status can be read are skipped; a status without a Tgid is reported as a
+// *ParseError.
+func WalkTGID(path string, fn func(pid uint64) error) error {
+
+	if filepath.Base(filepath.Clean(path)) != "task" {
+		return WalkPID(path, fn)
+	}
+
+	return WalkPID(path, func(pid uint64) error {
+
+		tgid, err := readStatusTgid(filepath.Join(path, strconv.FormatUint(pid, 10), "status"), nil)
+
+		if err != nil {
+			if err = processError(err); err == ErrProcessGone {
+				return nil
+			}
+			return err
+		}
+
+		if tgid != pid {
+			return nil
+		}
+
+		return fn(pid)
+	})
+}
+
+// ListPID returns the pids found in the proc directory at path which are not
+// greater than max, in ascending order.
 func ListPID(path string, max uint64) ([]uint64, error) {
 
 	l := make([]uint64, 0, 5)
 
-	for i := uint64(1); i <= max; i++ {
+	err := WalkPID(path, func(pid uint64) error {
+		if pid <= max {
+			l = append(l, pid)
+		}
+		return nil
+	})
 
-		p := filepath.Join(path, strconv.FormatUint(i, 10))
+	if err != nil {
+		return nil, err
+	}
 
-		s, err := os.Stat(p)
+	sort.Slice(l, func(i, j int) bool { return l[i] < l[j] })
 
-		if err != nil && !os.IsNotExist(err) {
-			return nil, err
-		}
+	return l, nil
+}
+
+// readStatusTgid reads only the Tgid line of a status file.
+func readStatusTgid(path string, onError ParseErrorHandler) (uint64, error) {
+
+	f, err := os.Open(path)
 
-		if err != nil || !s.IsDir() {
+	if err != nil {
+		return 0, err
+	}
+
+	defer f.Close()
+
+	p := newParser(path, onError)
+
+	scanner := bufio.NewScanner(f)
+
+	n := 0
+
+	for scanner.Scan() {
+
+		n++
+
+		line := scanner.Text()
+
+		if !strings.HasPrefix(line, "Tgid:") {
 			continue
 		}
 
-		l = append(l, i)
+		tgid, err := ParseUint(strings.TrimSpace(line[len("Tgid:"):]))
+
+		if err != nil {
+			return 0, p.fail(n, "Tgid", line, err)
+		}
 
+		return tgid, nil
 	}
 
-	return l, nil
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+
+	return 0, p.fail(n, "Tgid", "", ErrTooFewFields)
 }
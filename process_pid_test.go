@@ -2,6 +2,7 @@ package linuxtool
 
 import (
 	"reflect"
+	"sort"
 	"testing"
 )
 
@@ -36,3 +37,75 @@ func TestListPID(t *testing.T) {
 
 	t.Logf("%+v", list)
 }
+
+func TestWalkPID(t *testing.T) {
+
+	seen := map[uint64]bool{}
+
+	err := WalkPID("proc", func(pid uint64) error {
+		seen[pid] = true
+		return nil
+	})
+
+	if err != nil {
+		t.Fatal("walk pid fail", err)
+	}
+
+	var expected = map[uint64]bool{884: true, 3323: true, 4854: true, 5811: true}
+
+	if !reflect.DeepEqual(seen, expected) {
+		t.Error("not equal to expected", expected)
+	}
+
+	count := 0
+
+	err = WalkPID("proc", func(pid uint64) error {
+		count++
+		return ErrStopWalk
+	})
+
+	if err != nil {
+		t.Fatal("walk pid stop fail", err)
+	}
+
+	if count != 1 {
+		t.Error("walk did not stop after first pid")
+	}
+}
+
+func TestWalkTGID(t *testing.T) {
+
+	walk := func(path string) []uint64 {
+
+		list := make([]uint64, 0)
+
+		err := WalkTGID(path, func(pid uint64) error {
+			list = append(list, pid)
+			return nil
+		})
+
+		if err != nil {
+			t.Fatal("walk tgid fail", path, err)
+		}
+
+		sort.Slice(list, func(i, j int) bool { return list[i] < list[j] })
+
+		return list
+	}
+
+	// A proc directory only lists thread-group leaders.
+	if list := walk("proc"); !reflect.DeepEqual(list, []uint64{884, 3323, 4854, 5811}) {
+		t.Error("unexpected pids", list)
+	}
+
+	// 3324 is a thread of 3323.
+	if list := walk("proc/3323/task"); !reflect.DeepEqual(list, []uint64{3323}) {
+		t.Error("unexpected tids", list)
+	}
+
+	_, err := readStatusTgid("proc/status_truncated", nil)
+
+	if pe, ok := err.(*ParseError); !ok || pe.Field != "Tgid" || pe.Err != ErrTooFewFields {
+		t.Error("unexpected error", err)
+	}
+}
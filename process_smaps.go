@@ -0,0 +1,259 @@
+package linuxtool
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ProcessSmap is the memory usage of a mapping, from /proc/<pid>/smaps, or of
+// all the mappings of a process, from /proc/<pid>/smaps_rollup. Sizes are in
+// kB. Fields missing from older kernels are 0.
+//
+// SizeKB is the Size line, the size of the mapping in kB; the Size method of
+// the embedded ProcessMap returns it in bytes.
+type ProcessSmap struct {
+	ProcessMap
+	SizeKB         uint64   `json:"size" field:"Size"`
+	KernelPageSize uint64   `json:"kernel_page_size" field:"KernelPageSize"`
+	MMUPageSize    uint64   `json:"mmu_page_size" field:"MMUPageSize"`
+	Rss            uint64   `json:"rss" field:"Rss"`
+	Pss            uint64   `json:"pss" field:"Pss"`
+	PssAnon        uint64   `json:"pss_anon" field:"Pss_Anon"`   // smaps_rollup only
+	PssFile        uint64   `json:"pss_file" field:"Pss_File"`   // smaps_rollup only
+	PssShmem       uint64   `json:"pss_shmem" field:"Pss_Shmem"` // smaps_rollup only
+	SharedClean    uint64   `json:"shared_clean" field:"Shared_Clean"`
+	SharedDirty    uint64   `json:"shared_dirty" field:"Shared_Dirty"`
+	PrivateClean   uint64   `json:"private_clean" field:"Private_Clean"`
+	PrivateDirty   uint64   `json:"private_dirty" field:"Private_Dirty"`
+	Referenced     uint64   `json:"referenced" field:"Referenced"`
+	Anonymous      uint64   `json:"anonymous" field:"Anonymous"`
+	LazyFree       uint64   `json:"lazy_free" field:"LazyFree"`
+	AnonHugePages  uint64   `json:"anon_huge_pages" field:"AnonHugePages"`
+	ShmemPmdMapped uint64   `json:"shmem_pmd_mapped" field:"ShmemPmdMapped"`
+	SharedHugetlb  uint64   `json:"shared_hugetlb" field:"Shared_Hugetlb"`
+	PrivateHugetlb uint64   `json:"private_hugetlb" field:"Private_Hugetlb"`
+	Swap           uint64   `json:"swap" field:"Swap"`
+	SwapPss        uint64   `json:"swap_pss" field:"SwapPss"`
+	Locked         uint64   `json:"locked" field:"Locked"`
+	VmFlags        []string `json:"vm_flags"` // smaps only, e.g. rd, wr, ex
+}
+
+// ProcessMemoryUsage is the memory of a process accounted as smem does, in
+// kB. Rss counts shared pages in full in every process mapping them, Pss
+// divides them between those processes and Uss leaves them out, so Pss adds
+// up across processes and Uss is what is freed when the process exits.
+type ProcessMemoryUsage struct {
+	Rss           uint64 `json:"rss"`
+	Pss           uint64 `json:"pss"`
+	Uss           uint64 `json:"uss"`
+	Shared        uint64 `json:"shared"` // shared clean + shared dirty
+	Swap          uint64 `json:"swap"`
+	SwapPss       uint64 `json:"swap_pss"`
+	AnonHugePages uint64 `json:"anon_huge_pages"`
+	Locked        uint64 `json:"locked"`
+}
+
+// processSmapFields maps the keys of smaps to the index of their field.
+var processSmapFields = func() map[string]int {
+
+	t := reflect.TypeOf(ProcessSmap{})
+	m := make(map[string]int, t.NumField())
+
+	for i := 0; i < t.NumField(); i++ {
+		if k := t.Field(i).Tag.Get("field"); k != "" {
+			m[k] = i
+		}
+	}
+
+	return m
+}()
+
+// ReadProcessSmaps reads and parses the smaps file of a process.
+func ReadProcessSmaps(path string) ([]ProcessSmap, error) {
+	return readProcessSmaps(path, nil)
+}
+
+func readProcessSmaps(path string, onError ParseErrorHandler) ([]ProcessSmap, error) {
+
+	b, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	p := newParser(path, onError)
+
+	var smaps []ProcessSmap
+
+	// Index of the mapping the values belong to.
+	current := -1
+
+	lines := strings.Split(string(b), "\n")
+
+	for i, line := range lines {
+
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		k := line
+
+		if j := strings.IndexByte(line, ' '); j >= 0 {
+			k = line[:j]
+		}
+
+		// A header starts a new mapping; its first field, the address range,
+		// is the only one which does not end with a colon.
+		if !strings.HasSuffix(k, ":") {
+
+			m, err := parseProcessMap(line)
+
+			if err != nil {
+				if err = p.fail(i+1, "", line, err); err != nil {
+					return nil, err
+				}
+				current = -1
+				continue
+			}
+
+			smaps = append(smaps, ProcessSmap{ProcessMap: *m})
+			current = len(smaps) - 1
+			continue
+		}
+
+		// Values of a mapping whose header failed to parse are skipped.
+		if current < 0 {
+			continue
+		}
+
+		k = strings.TrimSuffix(k, ":")
+		v := strings.TrimSpace(line[len(k)+1:])
+
+		if k == "VmFlags" {
+			smaps[current].VmFlags = strings.Fields(v)
+			continue
+		}
+
+		field, ok := processSmapFields[k]
+
+		if !ok {
+			continue
+		}
+
+		n, err := ParseUint(strings.TrimSpace(strings.TrimSuffix(v, "kB")))
+
+		if err != nil {
+			if err = p.fail(i+1, k, line, err); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		reflect.ValueOf(&smaps[current]).Elem().Field(field).SetUint(n)
+	}
+
+	return smaps, nil
+}
+
+// ReadProcessSmapsRollup reads and parses the smaps_rollup file of a process
+// (Linux 4.14+), the sum of its smaps computed by the kernel. The file is empty
+// for processes without memory, such as kernel threads and zombies, which is
+// reported as ErrTooFewFields.
+func ReadProcessSmapsRollup(path string) (*ProcessSmap, error) {
+	return readProcessSmapsRollup(path, nil)
+}
+
+func readProcessSmapsRollup(path string, onError ParseErrorHandler) (*ProcessSmap, error) {
+
+	smaps, err := readProcessSmaps(path, onError)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(smaps) == 0 {
+		if err = newParser(path, onError).fail(1, "", "", ErrTooFewFields); err != nil {
+			return nil, err
+		}
+		return &ProcessSmap{}, nil
+	}
+
+	return &smaps[0], nil
+}
+
+// SumProcessSmaps adds up the usage of mappings, as smaps_rollup does.
+func SumProcessSmaps(smaps []ProcessSmap) *ProcessSmap {
+
+	sum := &ProcessSmap{ProcessMap: ProcessMap{Pathname: "[rollup]"}}
+
+	s := reflect.ValueOf(sum).Elem()
+
+	for i := range smaps {
+
+		v := reflect.ValueOf(&smaps[i]).Elem()
+
+		for _, field := range processSmapFields {
+			// Page sizes are per mapping, they don't add up.
+			if field == processSmapFields["KernelPageSize"] || field == processSmapFields["MMUPageSize"] {
+				continue
+			}
+			s.Field(field).SetUint(s.Field(field).Uint() + v.Field(field).Uint())
+		}
+	}
+
+	return sum
+}
+
+// Uss returns the unique set size, the memory private to the mappings.
+func (s *ProcessSmap) Uss() uint64 {
+	return s.PrivateClean + s.PrivateDirty
+}
+
+// MemoryUsage returns the smem-style usage of the mappings s sums up.
+func (s *ProcessSmap) MemoryUsage() *ProcessMemoryUsage {
+	return &ProcessMemoryUsage{
+		Rss:           s.Rss,
+		Pss:           s.Pss,
+		Uss:           s.Uss(),
+		Shared:        s.SharedClean + s.SharedDirty,
+		Swap:          s.Swap,
+		SwapPss:       s.SwapPss,
+		AnonHugePages: s.AnonHugePages,
+		Locked:        s.Locked,
+	}
+}
+
+// ReadProcessMemoryUsage reads the memory usage of the process pid from the
+// proc directory at path. It reads smaps_rollup, or sums smaps on kernels
+// without it. Kernel threads and zombies have no mappings, their smaps_rollup
+// is empty and their usage is zero.
+func ReadProcessMemoryUsage(pid uint64, path string) (*ProcessMemoryUsage, error) {
+	return readProcessMemoryUsage(pid, path, nil)
+}
+
+func readProcessMemoryUsage(pid uint64, path string, onError ParseErrorHandler) (*ProcessMemoryUsage, error) {
+
+	p := filepath.Join(path, strconv.FormatUint(pid, 10))
+
+	rollup, err := readProcessSmaps(filepath.Join(p, "smaps_rollup"), onError)
+
+	if err == nil {
+		return SumProcessSmaps(rollup).MemoryUsage(), nil
+	}
+
+	if !os.IsNotExist(err) {
+		return nil, processError(err)
+	}
+
+	smaps, err := readProcessSmaps(filepath.Join(p, "smaps"), onError)
+
+	if err != nil {
+		return nil, processError(err)
+	}
+
+	return SumProcessSmaps(smaps).MemoryUsage(), nil
+}
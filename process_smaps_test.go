@@ -0,0 +1,130 @@
+package linuxtool
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReadProcessSmaps(t *testing.T) {
+
+	smaps, err := ReadProcessSmaps("proc/3323/smaps")
+
+	if err != nil {
+		t.Fatal("process smaps read fail", err)
+	}
+
+	if len(smaps) != 178 {
+		t.Fatal("unexpected mapping count", len(smaps))
+	}
+
+	expected := ProcessSmap{
+		ProcessMap: ProcessMap{
+			StartAddr: 0x080f7000,
+			EndAddr:   0x080fe000,
+			Perms:     "rw-p",
+			Offset:    0xae000,
+			Major:     0xca,
+			Minor:     0,
+			Inode:     809,
+			Pathname:  "/usr/sbin/proftpd",
+		},
+		SizeKB:         28,
+		KernelPageSize: 4,
+		MMUPageSize:    4,
+		Rss:            28,
+		Pss:            28,
+		PrivateClean:   4,
+		PrivateDirty:   24,
+		Referenced:     24,
+		Anonymous:      24,
+	}
+
+	if !reflect.DeepEqual(smaps[2], expected) {
+		t.Errorf("unexpected mapping %+v", smaps[2])
+	}
+
+	sum := SumProcessSmaps(smaps)
+
+	if sum.Rss != 2088 || sum.Pss != 1656 || sum.Uss() != 1644 || sum.KernelPageSize != 0 {
+		t.Errorf("unexpected sum %+v", sum)
+	}
+}
+
+func TestReadProcessSmapsVmFlags(t *testing.T) {
+
+	smaps, err := ReadProcessSmaps("proc/4854/smaps")
+
+	if err != nil {
+		t.Fatal("process smaps read fail", err)
+	}
+
+	if len(smaps) != 2 || smaps[0].SwapPss != 4 || !reflect.DeepEqual(smaps[0].VmFlags, []string{"rd", "wr", "mr", "mw", "me", "ac"}) {
+		t.Errorf("unexpected mappings %+v", smaps)
+	}
+
+	if smaps[0].SizeKB != 1556 || smaps[0].Size() != 1556*1024 {
+		t.Errorf("unexpected size %+v", smaps[0])
+	}
+
+	if smaps[1].Pathname != "/SYSV00000000 (deleted)" || smaps[1].Rss != 4 || smaps[1].Minor != 5 {
+		t.Errorf("unexpected mapping %+v", smaps[1])
+	}
+}
+
+func TestReadProcessSmapsRollupEmpty(t *testing.T) {
+
+	_, err := ReadProcessSmapsRollup("proc/4854/smaps_rollup")
+
+	if pe, ok := err.(*ParseError); !ok || pe.Err != ErrTooFewFields {
+		t.Error("unexpected error", err)
+	}
+
+	rollup, err := readProcessSmapsRollup("proc/4854/smaps_rollup", SkipParseErrors)
+
+	if err != nil || rollup.Rss != 0 {
+		t.Error("unexpected rollup", rollup, err)
+	}
+}
+
+func TestReadProcessMemoryUsage(t *testing.T) {
+
+	expected := &ProcessMemoryUsage{Rss: 2088, Pss: 1656, Uss: 1644, Shared: 444}
+
+	usage, err := NewProcFS("proc").ProcessMemoryUsage(3323)
+
+	if err != nil {
+		t.Fatal("process memory read fail", err)
+	}
+
+	if !reflect.DeepEqual(usage, expected) {
+		t.Errorf("unexpected usage %+v", usage)
+	}
+
+	// A kernel without smaps_rollup: the task directories only have smaps.
+	dir := "proc/3323/task"
+
+	usage, err = ReadProcessMemoryUsage(3323, dir)
+
+	if err != nil {
+		t.Fatal("process memory read fail", err)
+	}
+
+	if !reflect.DeepEqual(usage, expected) {
+		t.Errorf("unexpected usage %+v", usage)
+	}
+
+	if _, err = ReadProcessMemoryUsage(99999, dir); err != ErrProcessGone {
+		t.Error("unexpected error", err)
+	}
+
+	// kthreadd: the smaps_rollup of kernel threads is empty.
+	usage, err = ReadProcessMemoryUsage(2, "proc/kthread")
+
+	if err != nil {
+		t.Fatal("process memory read fail", err)
+	}
+
+	if !reflect.DeepEqual(usage, &ProcessMemoryUsage{}) {
+		t.Errorf("unexpected usage %+v", usage)
+	}
+}
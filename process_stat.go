@@ -2,69 +2,81 @@ package linuxtool
 
 import (
 	"io/ioutil"
+	"reflect"
 	"regexp"
 	"strings"
+	"time"
 )
 
 // Status information about the process.
 type ProcessStat struct {
-	Pid                 uint64 `json:"pid"`
-	Comm                string `json:"comm"`
-	State               string `json:"state"`
-	Ppid                int64  `json:"ppid"`
-	Pgrp                int64  `json:"pgrp"`
-	Session             int64  `json:"session"`
-	TtyNr               int64  `json:"tty_nr"`
-	Tpgid               int64  `json:"tpgid"`
-	Flags               uint64 `json:"flags"`
-	Minflt              uint64 `json:"minflt"`
-	Cminflt             uint64 `json:"cminflt"`
-	Majflt              uint64 `json:"majflt"`
-	Cmajflt             uint64 `json:"cmajflt"`
-	Utime               uint64 `json:"utime"`
-	Stime               uint64 `json:"stime"`
-	Cutime              int64  `json:"cutime"`
-	Cstime              int64  `json:"cstime"`
-	Priority            int64  `json:"priority"`
-	Nice                int64  `json:"nice"`
-	NumThreads          int64  `json:"num_threads"`
-	Itrealvalue         int64  `json:"itrealvalue"`
-	Starttime           uint64 `json:"starttime"`
-	Vsize               uint64 `json:"vsize"`
-	Rss                 int64  `json:"rss"`
-	Rsslim              uint64 `json:"rsslim"`
-	Startcode           uint64 `json:"startcode"`
-	Endcode             uint64 `json:"endcode"`
-	Startstack          uint64 `json:"startstack"`
-	Kstkesp             uint64 `json:"kstkesp"`
-	Kstkeip             uint64 `json:"kstkeip"`
-	Signal              uint64 `json:"signal"`
-	Blocked             uint64 `json:"blocked"`
-	Sigignore           uint64 `json:"sigignore"`
-	Sigcatch            uint64 `json:"sigcatch"`
-	Wchan               uint64 `json:"wchan"`
-	Nswap               uint64 `json:"nswap"`
-	Cnswap              uint64 `json:"cnswap"`
-	ExitSignal          int64  `json:"exit_signal"`
-	Processor           int64  `json:"processor"`
-	RtPriority          uint64 `json:"rt_priority"`
-	Policy              uint64 `json:"policy"`
-	DelayacctBlkioTicks uint64 `json:"delayacct_blkio_ticks"`
-	GuestTime           uint64 `json:"guest_time"`
-	CguestTime          int64  `json:"cguest_time"`
-	StartData           uint64 `json:"start_data"`
-	EndData             uint64 `json:"end_data"`
-	StartBrk            uint64 `json:"start_brk"`
-	ArgStart            uint64 `json:"arg_start"`
-	ArgEnd              uint64 `json:"arg_end"`
-	EnvStart            uint64 `json:"env_start"`
-	EnvEnd              uint64 `json:"env_end"`
-	ExitCode            int64  `json:"exit_code"`
+	Pid                 uint64       `json:"pid"`
+	Comm                string       `json:"comm"`
+	State               ProcessState `json:"state"`
+	Ppid                int64        `json:"ppid"`
+	Pgrp                int64        `json:"pgrp"`
+	Session             int64        `json:"session"`
+	TtyNr               int64        `json:"tty_nr"`
+	Tpgid               int64        `json:"tpgid"`
+	Flags               ProcessFlags `json:"flags"`
+	Minflt              uint64       `json:"minflt"`
+	Cminflt             uint64       `json:"cminflt"`
+	Majflt              uint64       `json:"majflt"`
+	Cmajflt             uint64       `json:"cmajflt"`
+	Utime               uint64       `json:"utime"`
+	Stime               uint64       `json:"stime"`
+	Cutime              int64        `json:"cutime"`
+	Cstime              int64        `json:"cstime"`
+	Priority            int64        `json:"priority"`
+	Nice                int64        `json:"nice"`
+	NumThreads          int64        `json:"num_threads"`
+	Itrealvalue         int64        `json:"itrealvalue"`
+	Starttime           uint64       `json:"starttime"`
+	Vsize               uint64       `json:"vsize"`
+	Rss                 int64        `json:"rss"`
+	Rsslim              uint64       `json:"rsslim"`
+	Startcode           uint64       `json:"startcode"`
+	Endcode             uint64       `json:"endcode"`
+	Startstack          uint64       `json:"startstack"`
+	Kstkesp             uint64       `json:"kstkesp"`
+	Kstkeip             uint64       `json:"kstkeip"`
+	Signal              uint64       `json:"signal"`
+	Blocked             uint64       `json:"blocked"`
+	Sigignore           uint64       `json:"sigignore"`
+	Sigcatch            uint64       `json:"sigcatch"`
+	Wchan               uint64       `json:"wchan"`
+	Nswap               uint64       `json:"nswap"`
+	Cnswap              uint64       `json:"cnswap"`
+	ExitSignal          int64        `json:"exit_signal"`
+	Processor           int64        `json:"processor"`
+	RtPriority          uint64       `json:"rt_priority"`
+	Policy              SchedPolicy  `json:"policy"`
+	DelayacctBlkioTicks uint64       `json:"delayacct_blkio_ticks"`
+	GuestTime           uint64       `json:"guest_time"`
+	CguestTime          int64        `json:"cguest_time"`
+	StartData           uint64       `json:"start_data"`
+	EndData             uint64       `json:"end_data"`
+	StartBrk            uint64       `json:"start_brk"`
+	ArgStart            uint64       `json:"arg_start"`
+	ArgEnd              uint64       `json:"arg_end"`
+	EnvStart            uint64       `json:"env_start"`
+	EnvEnd              uint64       `json:"env_end"`
+	ExitCode            int64        `json:"exit_code"`
+}
+
+// processStatFieldName returns the json name of the i-th field of the stat
+// file, the fields of ProcessStat being declared in file order.
+func processStatFieldName(i int) string {
+	return reflect.TypeOf(ProcessStat{}).Field(i).Tag.Get("json")
 }
 
 var processStatRegExp = regexp.MustCompile(`^(\d+)( \(.*?\) )(.*)$`)
 
 func ReadProcessStat(path string) (*ProcessStat, error) {
+	return readProcessStat(path, nil)
+}
+
+func readProcessStat(path string, onError ParseErrorHandler) (*ProcessStat, error) {
 
 	b, err := ioutil.ReadFile(path)
 
@@ -72,11 +84,20 @@ func ReadProcessStat(path string) (*ProcessStat, error) {
 		return nil, err
 	}
 
-	s := string(b)
+	p := newParser(path, onError)
+
+	s := strings.TrimSpace(string(b))
 
 	f := make([]string, 0, 32)
 
-	e := processStatRegExp.FindStringSubmatch(strings.TrimSpace(s))
+	e := processStatRegExp.FindStringSubmatch(s)
+
+	if e == nil {
+		if err = p.fail(1, "", s, ErrTooFewFields); err != nil {
+			return nil, err
+		}
+		return &ProcessStat{}, nil
+	}
 
 	// Inject process Pid
 	f = append(f, e[1])
@@ -92,207 +113,113 @@ func ReadProcessStat(path string) (*ProcessStat, error) {
 	for i := 0; i < len(f); i++ {
 		switch i {
 		case 0:
-			if stat.Pid, err = ParseUint(f[i]); err != nil {
-				return nil, err
-			}
+			stat.Pid, err = ParseUint(f[i])
 		case 1:
 			stat.Comm = f[i]
 		case 2:
-			stat.State = f[i]
+			stat.State = ProcessState(f[i])
 		case 3:
-			if stat.Ppid, err = ParseInt(f[i]); err != nil {
-				return nil, err
-			}
+			stat.Ppid, err = ParseInt(f[i])
 		case 4:
-			if stat.Pgrp, err = ParseInt(f[i]); err != nil {
-				return nil, err
-			}
+			stat.Pgrp, err = ParseInt(f[i])
 		case 5:
-			if stat.Session, err = ParseInt(f[i]); err != nil {
-				return nil, err
-			}
+			stat.Session, err = ParseInt(f[i])
 		case 6:
-			if stat.TtyNr, err = ParseInt(f[i]); err != nil {
-				return nil, err
-			}
+			stat.TtyNr, err = ParseInt(f[i])
 		case 7:
-			if stat.Tpgid, err = ParseInt(f[i]); err != nil {
-				return nil, err
-			}
+			stat.Tpgid, err = ParseInt(f[i])
 		case 8:
-			if stat.Flags, err = ParseUint(f[i]); err != nil {
-				return nil, err
-			}
+			stat.Flags, err = parseProcessFlags(f[i])
 		case 9:
-			if stat.Minflt, err = ParseUint(f[i]); err != nil {
-				return nil, err
-			}
+			stat.Minflt, err = ParseUint(f[i])
 		case 10:
-			if stat.Cminflt, err = ParseUint(f[i]); err != nil {
-				return nil, err
-			}
+			stat.Cminflt, err = ParseUint(f[i])
 		case 11:
-			if stat.Majflt, err = ParseUint(f[i]); err != nil {
-				return nil, err
-			}
+			stat.Majflt, err = ParseUint(f[i])
 		case 12:
-			if stat.Cmajflt, err = ParseUint(f[i]); err != nil {
-				return nil, err
-			}
+			stat.Cmajflt, err = ParseUint(f[i])
 		case 13:
-			if stat.Utime, err = ParseUint(f[i]); err != nil {
-				return nil, err
-			}
+			stat.Utime, err = ParseUint(f[i])
 		case 14:
-			if stat.Stime, err = ParseUint(f[i]); err != nil {
-				return nil, err
-			}
+			stat.Stime, err = ParseUint(f[i])
 		case 15:
-			if stat.Cutime, err = ParseInt(f[i]); err != nil {
-				return nil, err
-			}
+			stat.Cutime, err = ParseInt(f[i])
 		case 16:
-			if stat.Cstime, err = ParseInt(f[i]); err != nil {
-				return nil, err
-			}
+			stat.Cstime, err = ParseInt(f[i])
 		case 17:
-			if stat.Priority, err = ParseInt(f[i]); err != nil {
-				return nil, err
-			}
+			stat.Priority, err = ParseInt(f[i])
 		case 18:
-			if stat.Nice, err = ParseInt(f[i]); err != nil {
-				return nil, err
-			}
+			stat.Nice, err = ParseInt(f[i])
 		case 19:
-			if stat.NumThreads, err = ParseInt(f[i]); err != nil {
-				return nil, err
-			}
+			stat.NumThreads, err = ParseInt(f[i])
 		case 20:
-			if stat.Itrealvalue, err = ParseInt(f[i]); err != nil {
-				return nil, err
-			}
+			stat.Itrealvalue, err = ParseInt(f[i])
 		case 21:
-			if stat.Starttime, err = ParseUint(f[i]); err != nil {
-				return nil, err
-			}
+			stat.Starttime, err = ParseUint(f[i])
 		case 22:
-			if stat.Vsize, err = ParseUint(f[i]); err != nil {
-				return nil, err
-			}
+			stat.Vsize, err = ParseUint(f[i])
 		case 23:
-			if stat.Rss, err = ParseInt(f[i]); err != nil {
-				return nil, err
-			}
+			stat.Rss, err = ParseInt(f[i])
 		case 24:
-			if stat.Rsslim, err = ParseUint(f[i]); err != nil {
-				return nil, err
-			}
+			stat.Rsslim, err = ParseUint(f[i])
 		case 25:
-			if stat.Startcode, err = ParseUint(f[i]); err != nil {
-				return nil, err
-			}
+			stat.Startcode, err = ParseUint(f[i])
 		case 26:
-			if stat.Endcode, err = ParseUint(f[i]); err != nil {
-				return nil, err
-			}
+			stat.Endcode, err = ParseUint(f[i])
 		case 27:
-			if stat.Startstack, err = ParseUint(f[i]); err != nil {
-				return nil, err
-			}
+			stat.Startstack, err = ParseUint(f[i])
 		case 28:
-			if stat.Kstkesp, err = ParseUint(f[i]); err != nil {
-				return nil, err
-			}
+			stat.Kstkesp, err = ParseUint(f[i])
 		case 29:
-			if stat.Kstkeip, err = ParseUint(f[i]); err != nil {
-				return nil, err
-			}
+			stat.Kstkeip, err = ParseUint(f[i])
 		case 30:
-			if stat.Signal, err = ParseUint(f[i]); err != nil {
-				return nil, err
-			}
+			stat.Signal, err = ParseUint(f[i])
 		case 31:
-			if stat.Blocked, err = ParseUint(f[i]); err != nil {
-				return nil, err
-			}
+			stat.Blocked, err = ParseUint(f[i])
 		case 32:
-			if stat.Sigignore, err = ParseUint(f[i]); err != nil {
-				return nil, err
-			}
+			stat.Sigignore, err = ParseUint(f[i])
 		case 33:
-			if stat.Sigcatch, err = ParseUint(f[i]); err != nil {
-				return nil, err
-			}
+			stat.Sigcatch, err = ParseUint(f[i])
 		case 34:
-			if stat.Wchan, err = ParseUint(f[i]); err != nil {
-				return nil, err
-			}
+			stat.Wchan, err = ParseUint(f[i])
 		case 35:
-			if stat.Nswap, err = ParseUint(f[i]); err != nil {
-				return nil, err
-			}
+			stat.Nswap, err = ParseUint(f[i])
 		case 36:
-			if stat.Cnswap, err = ParseUint(f[i]); err != nil {
-				return nil, err
-			}
+			stat.Cnswap, err = ParseUint(f[i])
 		case 37:
-			if stat.ExitSignal, err = ParseInt(f[i]); err != nil {
-				return nil, err
-			}
+			stat.ExitSignal, err = ParseInt(f[i])
 		case 38:
-			if stat.Processor, err = ParseInt(f[i]); err != nil {
-				return nil, err
-			}
+			stat.Processor, err = ParseInt(f[i])
 		case 39:
-			if stat.RtPriority, err = ParseUint(f[i]); err != nil {
-				return nil, err
-			}
+			stat.RtPriority, err = ParseUint(f[i])
 		case 40:
-			if stat.Policy, err = ParseUint(f[i]); err != nil {
-				return nil, err
-			}
+			stat.Policy, err = parseSchedPolicy(f[i])
 		case 41:
-			if stat.DelayacctBlkioTicks, err = ParseUint(f[i]); err != nil {
-				return nil, err
-			}
+			stat.DelayacctBlkioTicks, err = ParseUint(f[i])
 		case 42:
-			if stat.GuestTime, err = ParseUint(f[i]); err != nil {
-				return nil, err
-			}
+			stat.GuestTime, err = ParseUint(f[i])
 		case 43:
-			if stat.CguestTime, err = ParseInt(f[i]); err != nil {
-				return nil, err
-			}
+			stat.CguestTime, err = ParseInt(f[i])
 		case 44:
-			if stat.StartData, err = ParseUint(f[i]); err != nil {
-				return nil, err
-			}
+			stat.StartData, err = ParseUint(f[i])
 		case 45:
-			if stat.EndData, err = ParseUint(f[i]); err != nil {
-				return nil, err
-			}
+			stat.EndData, err = ParseUint(f[i])
 		case 46:
-			if stat.StartBrk, err = ParseUint(f[i]); err != nil {
-				return nil, err
-			}
+			stat.StartBrk, err = ParseUint(f[i])
 		case 47:
-			if stat.ArgStart, err = ParseUint(f[i]); err != nil {
-				return nil, err
-			}
+			stat.ArgStart, err = ParseUint(f[i])
 		case 48:
-			if stat.ArgEnd, err = ParseUint(f[i]); err != nil {
-				return nil, err
-			}
+			stat.ArgEnd, err = ParseUint(f[i])
 		case 49:
-			if stat.EnvStart, err = ParseUint(f[i]); err != nil {
-				return nil, err
-			}
+			stat.EnvStart, err = ParseUint(f[i])
 		case 50:
-			if stat.EnvEnd, err = ParseUint(f[i]); err != nil {
-				return nil, err
-			}
+			stat.EnvEnd, err = ParseUint(f[i])
 		case 51:
-			if stat.ExitCode, err = ParseInt(f[i]); err != nil {
+			stat.ExitCode, err = ParseInt(f[i])
+		}
+
+		if err != nil {
+			if err = p.fail(1, processStatFieldName(i), s, err); err != nil {
 				return nil, err
 			}
 		}
@@ -300,3 +227,40 @@ func ReadProcessStat(path string) (*ProcessStat, error) {
 
 	return &stat, nil
 }
+
+// StartTime returns when the process started, given the boot time of the
+// system, Stat.BootTime.
+func (s *ProcessStat) StartTime(bootTime time.Time) time.Time {
+	return bootTime.Add(TicksToDuration(s.Starttime))
+}
+
+// Age returns how long the process has been running at now.
+func (s *ProcessStat) Age(bootTime time.Time, now time.Time) time.Duration {
+	return now.Sub(s.StartTime(bootTime))
+}
+
+// UserTime returns the time the process was scheduled in user mode.
+func (s *ProcessStat) UserTime() time.Duration {
+	return TicksToDuration(s.Utime)
+}
+
+// SystemTime returns the time the process was scheduled in kernel mode.
+func (s *ProcessStat) SystemTime() time.Duration {
+	return TicksToDuration(s.Stime)
+}
+
+// CPUTime returns the CPU time the process used, in user and kernel mode, as
+// the TIME column of ps.
+func (s *ProcessStat) CPUTime() time.Duration {
+	return TicksToDuration(s.Utime + s.Stime)
+}
+
+// RssBytes returns the resident set size in bytes.
+func (s *ProcessStat) RssBytes() uint64 {
+
+	if s.Rss < 0 {
+		return 0
+	}
+
+	return uint64(s.Rss) * uint64(PageSize())
+}
@@ -3,6 +3,7 @@ package linuxtool
 import (
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestReadProcessStat(t *testing.T) {
@@ -191,3 +192,33 @@ func TestReadProcessStatWithDoubleParentheses(t *testing.T) {
 	t.Logf("%+v", stat)
 
 }
+
+func TestProcessStatUnits(t *testing.T) {
+
+	stat, err := ReadProcessStat("proc/3323/stat")
+
+	if err != nil {
+		t.Fatal("process stat read fail", err)
+	}
+
+	bootTime := time.Unix(1384677090, 0)
+	tick := time.Second / time.Duration(ClockTicks())
+
+	if start := stat.StartTime(bootTime); !start.Equal(bootTime.Add(2789 * tick)) {
+		t.Error("unexpected start time", start)
+	}
+
+	now := bootTime.Add(time.Hour)
+
+	if age := stat.Age(bootTime, now); age != time.Hour-2789*tick {
+		t.Error("unexpected age", age)
+	}
+
+	if stat.UserTime() != 23*tick || stat.SystemTime() != 58*tick || stat.CPUTime() != 81*tick {
+		t.Error("unexpected cpu time", stat.UserTime(), stat.SystemTime(), stat.CPUTime())
+	}
+
+	if stat.RssBytes() != 522*uint64(PageSize()) {
+		t.Error("unexpected rss", stat.RssBytes())
+	}
+}
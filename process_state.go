@@ -0,0 +1,208 @@
+package linuxtool
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ProcessState is the state letter of a process, the third field of
+// /proc/<pid>/stat.
+type ProcessState string
+
+const (
+	ProcessRunning     ProcessState = "R"
+	ProcessSleeping    ProcessState = "S" // interruptible sleep
+	ProcessDiskSleep   ProcessState = "D" // uninterruptible sleep, usually I/O
+	ProcessZombie      ProcessState = "Z"
+	ProcessStopped     ProcessState = "T" // stopped by a signal
+	ProcessTracingStop ProcessState = "t"
+	ProcessDead        ProcessState = "X"
+	ProcessWakeKill    ProcessState = "K" // Linux 2.6.33 to 3.13
+	ProcessWaking      ProcessState = "W" // Linux 2.6.33 to 3.13
+	ProcessParked      ProcessState = "P" // Linux 3.9 to 3.13
+	ProcessIdle        ProcessState = "I" // idle kernel thread, Linux 4.14+
+)
+
+var processStateNames = map[ProcessState]string{
+	ProcessRunning:     "running",
+	ProcessSleeping:    "sleeping",
+	ProcessDiskSleep:   "disk sleep",
+	ProcessZombie:      "zombie",
+	ProcessStopped:     "stopped",
+	ProcessTracingStop: "tracing stop",
+	ProcessDead:        "dead",
+	ProcessWakeKill:    "wakekill",
+	ProcessWaking:      "waking",
+	ProcessParked:      "parked",
+	ProcessIdle:        "idle",
+}
+
+// Name returns the name of the state as in /proc/<pid>/status, e.g.
+// "disk sleep", or the letter itself if it is unknown.
+func (s ProcessState) Name() string {
+
+	if name, ok := processStateNames[s]; ok {
+		return name
+	}
+
+	return string(s)
+}
+
+// CountProcessStates returns the number of processes in each state, e.g. the
+// processes in ProcessDiskSleep during an I/O stall.
+func CountProcessStates(processes []Process) map[ProcessState]int {
+
+	counts := make(map[ProcessState]int)
+
+	for i := range processes {
+		counts[processes[i].Stat.State]++
+	}
+
+	return counts
+}
+
+// SchedPolicy is the scheduling policy of a process, the SCHED_* constants of
+// sched(7).
+type SchedPolicy uint64
+
+const (
+	SchedOther    SchedPolicy = 0
+	SchedFIFO     SchedPolicy = 1
+	SchedRR       SchedPolicy = 2
+	SchedBatch    SchedPolicy = 3
+	SchedISO      SchedPolicy = 4 // reserved, not implemented
+	SchedIdle     SchedPolicy = 5
+	SchedDeadline SchedPolicy = 6
+)
+
+var schedPolicyNames = []string{
+	"SCHED_OTHER", "SCHED_FIFO", "SCHED_RR", "SCHED_BATCH", "SCHED_ISO", "SCHED_IDLE", "SCHED_DEADLINE",
+}
+
+// String returns the name of the policy, e.g. "SCHED_FIFO".
+func (p SchedPolicy) String() string {
+
+	if p < SchedPolicy(len(schedPolicyNames)) {
+		return schedPolicyNames[p]
+	}
+
+	return "SCHED_" + strconv.FormatUint(uint64(p), 10)
+}
+
+func parseSchedPolicy(s string) (SchedPolicy, error) {
+	n, err := ParseUint(s)
+	return SchedPolicy(n), err
+}
+
+// IsRealtime reports whether the policy is one of the real-time ones, which
+// preempt all others.
+func (p SchedPolicy) IsRealtime() bool {
+	return p == SchedFIFO || p == SchedRR || p == SchedDeadline
+}
+
+// ProcessFlags are the PF_* flags of a process, the flags field of
+// /proc/<pid>/stat. Some bits were reassigned between kernel versions; the
+// names are the ones of Linux 6.4 and later. On older kernels 0x10 was
+// PF_VCPU before 5.12 and 0x10000 PF_FROZEN before 6.1.
+type ProcessFlags uint64
+
+const (
+	PFVCPU          ProcessFlags = 0x00000001
+	PFIdle          ProcessFlags = 0x00000002
+	PFExiting       ProcessFlags = 0x00000004
+	PFPostCoreDump  ProcessFlags = 0x00000008
+	PFIOWorker      ProcessFlags = 0x00000010
+	PFWQWorker      ProcessFlags = 0x00000020
+	PFForkNoExec    ProcessFlags = 0x00000040
+	PFMCEProcess    ProcessFlags = 0x00000080
+	PFSuperPriv     ProcessFlags = 0x00000100
+	PFDumpCore      ProcessFlags = 0x00000200
+	PFSignaled      ProcessFlags = 0x00000400
+	PFMemalloc      ProcessFlags = 0x00000800
+	PFNprocExceeded ProcessFlags = 0x00001000
+	PFUsedMath      ProcessFlags = 0x00002000
+	PFUserWorker    ProcessFlags = 0x00004000
+	PFNoFreeze      ProcessFlags = 0x00008000
+	PFKswapd        ProcessFlags = 0x00020000
+	PFMemallocNoFS  ProcessFlags = 0x00040000
+	PFMemallocNoIO  ProcessFlags = 0x00080000
+	PFLocalThrottle ProcessFlags = 0x00100000
+	PFKthread       ProcessFlags = 0x00200000
+	PFRandomize     ProcessFlags = 0x00400000
+	PFNoSetAffinity ProcessFlags = 0x04000000
+	PFMCEEarly      ProcessFlags = 0x08000000
+	PFMemallocPin   ProcessFlags = 0x10000000
+	PFSuspendTask   ProcessFlags = 0x80000000
+)
+
+var processFlagNames = map[ProcessFlags]string{
+	PFVCPU:          "PF_VCPU",
+	PFIdle:          "PF_IDLE",
+	PFExiting:       "PF_EXITING",
+	PFPostCoreDump:  "PF_POSTCOREDUMP",
+	PFIOWorker:      "PF_IO_WORKER",
+	PFWQWorker:      "PF_WQ_WORKER",
+	PFForkNoExec:    "PF_FORKNOEXEC",
+	PFMCEProcess:    "PF_MCE_PROCESS",
+	PFSuperPriv:     "PF_SUPERPRIV",
+	PFDumpCore:      "PF_DUMPCORE",
+	PFSignaled:      "PF_SIGNALED",
+	PFMemalloc:      "PF_MEMALLOC",
+	PFNprocExceeded: "PF_NPROC_EXCEEDED",
+	PFUsedMath:      "PF_USED_MATH",
+	PFUserWorker:    "PF_USER_WORKER",
+	PFNoFreeze:      "PF_NOFREEZE",
+	PFKswapd:        "PF_KSWAPD",
+	PFMemallocNoFS:  "PF_MEMALLOC_NOFS",
+	PFMemallocNoIO:  "PF_MEMALLOC_NOIO",
+	PFLocalThrottle: "PF_LOCAL_THROTTLE",
+	PFKthread:       "PF_KTHREAD",
+	PFRandomize:     "PF_RANDOMIZE",
+	PFNoSetAffinity: "PF_NO_SETAFFINITY",
+	PFMCEEarly:      "PF_MCE_EARLY",
+	PFMemallocPin:   "PF_MEMALLOC_PIN",
+	PFSuspendTask:   "PF_SUSPEND_TASK",
+}
+
+func parseProcessFlags(s string) (ProcessFlags, error) {
+	n, err := ParseUint(s)
+	return ProcessFlags(n), err
+}
+
+// Has reports whether all of flags are set.
+func (f ProcessFlags) Has(flags ProcessFlags) bool {
+	return f&flags == flags
+}
+
+// List returns the names of the flags set, in bit order. Unnamed bits are
+// listed in hex.
+func (f ProcessFlags) List() []string {
+
+	var names []string
+
+	for bit := ProcessFlags(1); bit != 0 && bit <= f; bit <<= 1 {
+
+		if f&bit == 0 {
+			continue
+		}
+
+		if name, ok := processFlagNames[bit]; ok {
+			names = append(names, name)
+		} else {
+			names = append(names, "0x"+strconv.FormatUint(uint64(bit), 16))
+		}
+	}
+
+	return names
+}
+
+// String returns the names of the flags set, separated by "|".
+func (f ProcessFlags) String() string {
+	return strings.Join(f.List(), "|")
+}
+
+// IsKernelThread reports whether the process is a kernel thread, like
+// kthreadd and its children.
+func (s *ProcessStat) IsKernelThread() bool {
+	return s.Flags.Has(PFKthread)
+}
@@ -0,0 +1,64 @@
+package linuxtool
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestProcessStatEnums(t *testing.T) {
+
+	stat, err := ReadProcessStat("proc/3323/stat")
+
+	if err != nil {
+		t.Fatal("process stat read fail", err)
+	}
+
+	if stat.State != ProcessSleeping || stat.State.Name() != "sleeping" {
+		t.Error("unexpected state", stat.State)
+	}
+
+	if stat.Policy != SchedOther || stat.Policy.String() != "SCHED_OTHER" || stat.Policy.IsRealtime() {
+		t.Error("unexpected policy", stat.Policy)
+	}
+
+	if stat.Flags.String() != "PF_FORKNOEXEC|PF_SUPERPRIV|PF_USED_MATH|PF_RANDOMIZE" {
+		t.Error("unexpected flags", stat.Flags)
+	}
+
+	if stat.IsKernelThread() {
+		t.Error("unexpected kernel thread")
+	}
+
+	// A kworker of Linux 6.x.
+	kworker := ProcessStat{State: ProcessIdle, Flags: 0x04208060, Policy: SchedFIFO}
+
+	if !kworker.IsKernelThread() || !kworker.Flags.Has(PFWQWorker|PFNoSetAffinity) {
+		t.Error("unexpected kworker flags", kworker.Flags)
+	}
+
+	if kworker.State.Name() != "idle" || !kworker.Policy.IsRealtime() {
+		t.Error("unexpected kworker", kworker.State.Name(), kworker.Policy)
+	}
+
+	// Bits reassigned in 5.12.
+	if ProcessFlags(0x10).String() != "PF_IO_WORKER" || ProcessFlags(0x1).String() != "PF_VCPU" {
+		t.Error("unexpected flags", ProcessFlags(0x11))
+	}
+
+	// A bit unused since PF_FROZEN was removed in 6.1.
+	if ProcessFlags(0x10000).String() != "0x10000" || SchedPolicy(9).String() != "SCHED_9" || ProcessState("?").Name() != "?" {
+		t.Error("unexpected names of unknown values")
+	}
+
+	processes := []Process{
+		{Stat: ProcessStat{State: ProcessDiskSleep}},
+		{Stat: ProcessStat{State: ProcessSleeping}},
+		{Stat: ProcessStat{State: ProcessDiskSleep}},
+	}
+
+	expected := map[ProcessState]int{ProcessDiskSleep: 2, ProcessSleeping: 1}
+
+	if counts := CountProcessStates(processes); !reflect.DeepEqual(counts, expected) {
+		t.Error("unexpected counts", counts)
+	}
+}
@@ -16,7 +16,13 @@ type ProcessStatm struct {
 	Dirty    uint64 `json:"dirty"`    // dirty pages (unused in Linux 2.6)
 }
 
+var processStatmFields = []string{"size", "resident", "share", "text", "lib", "data", "dirty"}
+
 func ReadProcessStatm(path string) (*ProcessStatm, error) {
+	return readProcessStatm(path, nil)
+}
+
+func readProcessStatm(path string, onError ParseErrorHandler) (*ProcessStatm, error) {
 
 	b, err := ioutil.ReadFile(path)
 
@@ -24,6 +30,8 @@ func ReadProcessStatm(path string) (*ProcessStatm, error) {
 		return nil, err
 	}
 
+	p := newParser(path, onError)
+
 	s := string(b)
 	f := strings.Fields(s)
 
@@ -31,10 +39,13 @@ func ReadProcessStatm(path string) (*ProcessStatm, error) {
 
 	var n uint64
 
-	for i := 0; i < len(f); i++ {
+	for i := 0; i < len(f) && i < len(processStatmFields); i++ {
 
 		if n, err = ParseUint(f[i]); err != nil {
-			return nil, err
+			if err = p.fail(1, processStatmFields[i], strings.TrimSpace(s), err); err != nil {
+				return nil, err
+			}
+			continue
 		}
 
 		switch i {
@@ -58,3 +69,29 @@ func ReadProcessStatm(path string) (*ProcessStatm, error) {
 
 	return &statm, nil
 }
+
+// SizeBytes returns the total program size in bytes.
+func (s *ProcessStatm) SizeBytes() uint64 {
+	return s.Size * uint64(PageSize())
+}
+
+// ResidentBytes returns the resident set size in bytes.
+func (s *ProcessStatm) ResidentBytes() uint64 {
+	return s.Resident * uint64(PageSize())
+}
+
+// ShareBytes returns the resident shared memory, file backed or shmem, in
+// bytes.
+func (s *ProcessStatm) ShareBytes() uint64 {
+	return s.Share * uint64(PageSize())
+}
+
+// TextBytes returns the size of the code in bytes.
+func (s *ProcessStatm) TextBytes() uint64 {
+	return s.Text * uint64(PageSize())
+}
+
+// DataBytes returns the size of the data and stack in bytes.
+func (s *ProcessStatm) DataBytes() uint64 {
+	return s.Data * uint64(PageSize())
+}
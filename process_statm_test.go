@@ -29,3 +29,19 @@ func TestReadProcessStatm(t *testing.T) {
 
 	t.Logf("%+v", statm)
 }
+
+func TestProcessStatmBytes(t *testing.T) {
+
+	statm, err := ReadProcessStatm("proc/3323/statm")
+
+	if err != nil {
+		t.Fatal("process statm read fail", err)
+	}
+
+	page := uint64(PageSize())
+
+	if statm.SizeBytes() != 4053*page || statm.ResidentBytes() != 522*page || statm.ShareBytes() != 174*page ||
+		statm.TextBytes() != 174*page || statm.DataBytes() != 286*page {
+		t.Errorf("unexpected bytes of %+v", statm)
+	}
+}
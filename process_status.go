@@ -9,6 +9,7 @@ import (
 // Provides much of the information from ProcessStatm and ProcessStat
 type ProcessStatus struct {
 	Name                     string
+	Umask                    uint32 // Linux 4.7+
 	State                    string
 	Tgid                     uint64
 	Pid                      uint64
@@ -24,37 +25,62 @@ type ProcessStatus struct {
 	FilesystemGid            uint64
 	FDSize                   uint64
 	Groups                   []int64
+	NStgid                   []uint64 // ids in each pid namespace, outermost first, Linux 4.1+
+	NSpid                    []uint64
+	NSpgid                   []uint64
+	NSsid                    []uint64
 	VmPeak                   uint64
 	VmSize                   uint64
 	VmLck                    uint64
 	VmHWM                    uint64
 	VmRSS                    uint64
+	RssAnon                  uint64 // Linux 4.5+
+	RssFile                  uint64
+	RssShmem                 uint64
 	VmData                   uint64
 	VmStk                    uint64
 	VmExe                    uint64
 	VmLib                    uint64
 	VmPTE                    uint64
+	VmPMD                    uint64
 	VmSwap                   uint64
+	HugetlbPages             uint64
+	CoreDumping              bool
+	THPEnabled               bool
 	Threads                  uint64
 	SigQLength               uint64
 	SigQLimit                uint64
-	SigPnd                   uint64
-	ShdPnd                   uint64
-	SigBlk                   uint64
-	SigIgn                   uint64
-	SigCgt                   uint64
-	CapInh                   uint64
-	CapPrm                   uint64
-	CapEff                   uint64
-	CapBnd                   uint64
+	SigPnd                   SignalSet
+	ShdPnd                   SignalSet
+	SigBlk                   SignalSet
+	SigIgn                   SignalSet
+	SigCgt                   SignalSet
+	CapInh                   CapabilitySet
+	CapPrm                   CapabilitySet
+	CapEff                   CapabilitySet
+	CapBnd                   CapabilitySet
+	CapAmb                   CapabilitySet // Linux 4.3+
+	NoNewPrivs               bool
 	Seccomp                  uint8
+	SeccompFilters           uint64
+	SpeculationStoreBypass   string // e.g. "thread vulnerable"
 	CpusAllowed              []uint32
+	CpusAllowedList          []int
 	MemsAllowed              []uint32
+	MemsAllowedList          []int
 	VoluntaryCtxtSwitches    uint64
 	NonvoluntaryCtxtSwitches uint64
+
+	// Fields not known to ProcessStatus, by name, so that those of newer
+	// kernels are not lost.
+	Extra map[string]string
 }
 
 func ReadProcessStatus(path string) (*ProcessStatus, error) {
+	return readProcessStatus(path, nil)
+}
+
+func readProcessStatus(path string, onError ParseErrorHandler) (*ProcessStatus, error) {
 
 	b, err := ioutil.ReadFile(path)
 
@@ -62,17 +88,19 @@ func ReadProcessStatus(path string) (*ProcessStatus, error) {
 		return nil, err
 	}
 
+	p := newParser(path, onError)
+
 	status := ProcessStatus{}
 
 	lines := strings.Split(string(b), "\n")
 
-	for _, line := range lines {
+	for n, line := range lines {
 
 		if !strings.Contains(line, ":") {
 			continue
 		}
 
-		l := strings.Split(line, ":")
+		l := strings.SplitN(line, ":", 2)
 
 		k := strings.TrimSpace(l[0])
 		v := strings.TrimSpace(l[1])
@@ -80,58 +108,31 @@ func ReadProcessStatus(path string) (*ProcessStatus, error) {
 		switch k {
 		case "Name":
 			status.Name = v
+		case "Umask":
+			{
+
+				var n uint64
+
+				if n, err = strconv.ParseUint(v, 8, 32); err == nil {
+					status.Umask = uint32(n)
+				}
+			}
 		case "State":
 			status.State = v
 		case "Tgid":
-			if status.Tgid, err = ParseUint(v); err != nil {
-				return nil, err
-			}
+			status.Tgid, err = ParseUint(v)
 		case "Pid":
-			if status.Pid, err = ParseUint(v); err != nil {
-				return nil, err
-			}
+			status.Pid, err = ParseUint(v)
 		case "PPid":
-			if status.PPid, err = ParseInt(v); err != nil {
-				return nil, err
-			}
+			status.PPid, err = ParseInt(v)
 		case "TracerPid":
-			if status.TracerPid, err = ParseUint(v); err != nil {
-				return nil, err
-			}
+			status.TracerPid, err = ParseUint(v)
 		case "Uid":
-			if f := strings.Fields(v); len(f) == 4 {
-				if status.RealUid, err = ParseUint(f[0]); err != nil {
-					return nil, err
-				}
-				if status.EffectiveUid, err = ParseUint(f[1]); err != nil {
-					return nil, err
-				}
-				if status.SavedSetUid, err = ParseUint(f[2]); err != nil {
-					return nil, err
-				}
-				if status.FilesystemUid, err = ParseUint(f[3]); err != nil {
-					return nil, err
-				}
-			}
+			err = parseStatusIDs(v, &status.RealUid, &status.EffectiveUid, &status.SavedSetUid, &status.FilesystemUid)
 		case "Gid":
-			if f := strings.Fields(v); len(f) == 4 {
-				if status.RealGid, err = ParseUint(f[0]); err != nil {
-					return nil, err
-				}
-				if status.EffectiveGid, err = ParseUint(f[1]); err != nil {
-					return nil, err
-				}
-				if status.SavedSetGid, err = ParseUint(f[2]); err != nil {
-					return nil, err
-				}
-				if status.FilesystemGid, err = ParseUint(f[3]); err != nil {
-					return nil, err
-				}
-			}
+			err = parseStatusIDs(v, &status.RealGid, &status.EffectiveGid, &status.SavedSetGid, &status.FilesystemGid)
 		case "FDSize":
-			if status.FDSize, err = ParseUint(v); err != nil {
-				return nil, err
-			}
+			status.FDSize, err = ParseUint(v)
 		case "Groups":
 			{
 
@@ -140,192 +141,220 @@ func ReadProcessStatus(path string) (*ProcessStatus, error) {
 
 				for i := range status.Groups {
 					if status.Groups[i], err = ParseInt(f[i]); err != nil {
-						return nil, err
+						break
 					}
 				}
 
 			}
+		case "NStgid":
+			status.NStgid, err = parseStatusUints(v)
+		case "NSpid":
+			status.NSpid, err = parseStatusUints(v)
+		case "NSpgid":
+			status.NSpgid, err = parseStatusUints(v)
+		case "NSsid":
+			status.NSsid, err = parseStatusUints(v)
 		case "VmPeak":
-			{
-				f := strings.Fields(v)
-				if status.VmPeak, err = ParseUint(f[0]); err != nil {
-					return nil, err
-				}
-			}
+			status.VmPeak, err = parseStatusKB(v)
 		case "VmSize":
-			{
-				f := strings.Fields(v)
-				if status.VmSize, err = ParseUint(f[0]); err != nil {
-					return nil, err
-				}
-			}
+			status.VmSize, err = parseStatusKB(v)
 		case "VmLck":
-			{
-				f := strings.Fields(v)
-				if status.VmLck, err = ParseUint(f[0]); err != nil {
-					return nil, err
-				}
-			}
+			status.VmLck, err = parseStatusKB(v)
 		case "VmHWM":
-			{
-				f := strings.Fields(v)
-				if status.VmHWM, err = ParseUint(f[0]); err != nil {
-					return nil, err
-				}
-			}
+			status.VmHWM, err = parseStatusKB(v)
 		case "VmRSS":
-			{
-				f := strings.Fields(v)
-				if status.VmRSS, err = ParseUint(f[0]); err != nil {
-					return nil, err
-				}
-			}
+			status.VmRSS, err = parseStatusKB(v)
+		case "RssAnon":
+			status.RssAnon, err = parseStatusKB(v)
+		case "RssFile":
+			status.RssFile, err = parseStatusKB(v)
+		case "RssShmem":
+			status.RssShmem, err = parseStatusKB(v)
 		case "VmData":
-			{
-				f := strings.Fields(v)
-				if status.VmData, err = ParseUint(f[0]); err != nil {
-					return nil, err
-				}
-			}
+			status.VmData, err = parseStatusKB(v)
 		case "VmStk":
-			{
-				f := strings.Fields(v)
-				if status.VmStk, err = ParseUint(f[0]); err != nil {
-					return nil, err
-				}
-			}
+			status.VmStk, err = parseStatusKB(v)
 		case "VmExe":
-			{
-				f := strings.Fields(v)
-				if status.VmExe, err = ParseUint(f[0]); err != nil {
-					return nil, err
-				}
-			}
+			status.VmExe, err = parseStatusKB(v)
 		case "VmLib":
-			{
-				f := strings.Fields(v)
-				if status.VmLib, err = ParseUint(f[0]); err != nil {
-					return nil, err
-				}
-			}
+			status.VmLib, err = parseStatusKB(v)
 		case "VmPTE":
-			{
-				f := strings.Fields(v)
-				if status.VmPTE, err = ParseUint(f[0]); err != nil {
-					return nil, err
-				}
-			}
+			status.VmPTE, err = parseStatusKB(v)
+		case "VmPMD":
+			status.VmPMD, err = parseStatusKB(v)
 		case "VmSwap":
-			{
-				f := strings.Fields(v)
-				if status.VmSwap, err = ParseUint(f[0]); err != nil {
-					return nil, err
-				}
-			}
+			status.VmSwap, err = parseStatusKB(v)
+		case "HugetlbPages":
+			status.HugetlbPages, err = parseStatusKB(v)
+		case "CoreDumping":
+			status.CoreDumping, err = parseStatusBool(v)
+		case "THP_enabled":
+			status.THPEnabled, err = parseStatusBool(v)
 		case "Threads":
-			if status.Threads, err = ParseUint(v); err != nil {
-				return nil, err
-			}
+			status.Threads, err = ParseUint(v)
 		case "SigQ":
 			{
 				if f := strings.Split(v, "/"); len(f) == 2 {
-					if status.SigQLength, err = ParseUint(f[0]); err != nil {
-						return nil, err
-					}
-					if status.SigQLimit, err = ParseUint(f[1]); err != nil {
-						return nil, err
+					if status.SigQLength, err = ParseUint(f[0]); err == nil {
+						status.SigQLimit, err = ParseUint(f[1])
 					}
 				}
 			}
 		case "SigPnd":
-			if status.SigPnd, err = ParseHexUint(v); err != nil {
-				return nil, err
-			}
+			status.SigPnd, err = parseStatusSignals(v)
 		case "ShdPnd":
-			if status.ShdPnd, err = ParseHexUint(v); err != nil {
-				return nil, err
-			}
+			status.ShdPnd, err = parseStatusSignals(v)
 		case "SigBlk":
-			if status.SigBlk, err = ParseHexUint(v); err != nil {
-				return nil, err
-			}
+			status.SigBlk, err = parseStatusSignals(v)
 		case "SigIgn":
-			if status.SigIgn, err = ParseHexUint(v); err != nil {
-				return nil, err
-			}
+			status.SigIgn, err = parseStatusSignals(v)
 		case "SigCgt":
-			if status.SigCgt, err = ParseHexUint(v); err != nil {
-				return nil, err
-			}
+			status.SigCgt, err = parseStatusSignals(v)
 		case "CapInh":
-			if status.CapInh, err = ParseHexUint(v); err != nil {
-				return nil, err
-			}
+			status.CapInh, err = parseStatusCapabilities(v)
 		case "CapPrm":
-			if status.CapPrm, err = ParseHexUint(v); err != nil {
-				return nil, err
-			}
+			status.CapPrm, err = parseStatusCapabilities(v)
 		case "CapEff":
-			if status.CapEff, err = ParseHexUint(v); err != nil {
-				return nil, err
-			}
+			status.CapEff, err = parseStatusCapabilities(v)
 		case "CapBnd":
-			if status.CapBnd, err = ParseHexUint(v); err != nil {
-				return nil, err
-			}
+			status.CapBnd, err = parseStatusCapabilities(v)
+		case "CapAmb":
+			status.CapAmb, err = parseStatusCapabilities(v)
+		case "NoNewPrivs":
+			status.NoNewPrivs, err = parseStatusBool(v)
 		case "Seccomp":
 			{
 
 				var n uint64
 
-				if n, err = strconv.ParseUint(v, 10, 8); err != nil {
-					return nil, err
+				if n, err = strconv.ParseUint(v, 10, 8); err == nil {
+					status.Seccomp = uint8(n)
 				}
-
-				status.Seccomp = uint8(n)
 			}
+		case "Seccomp_filters":
+			status.SeccompFilters, err = ParseUint(v)
+		case "Speculation_Store_Bypass":
+			status.SpeculationStoreBypass = v
 		case "Cpus_allowed":
-			{
+			status.CpusAllowed, err = parseStatusMask(v)
+		case "Cpus_allowed_list":
+			status.CpusAllowedList, err = ParseRangeList(v)
+		case "Mems_allowed":
+			status.MemsAllowed, err = parseStatusMask(v)
+		case "Mems_allowed_list":
+			status.MemsAllowedList, err = ParseRangeList(v)
+		case "voluntary_ctxt_switches":
+			status.VoluntaryCtxtSwitches, err = ParseUint(v)
+		case "nonvoluntary_ctxt_switches":
+			status.NonvoluntaryCtxtSwitches, err = ParseUint(v)
+		default:
+			if status.Extra == nil {
+				status.Extra = make(map[string]string)
+			}
+			status.Extra[k] = v
+		}
 
-				var n uint64
+		if err != nil {
+			if err = p.fail(n+1, k, line, err); err != nil {
+				return nil, err
+			}
+		}
+	}
 
-				f := strings.Split(v, ",")
-				status.CpusAllowed = make([]uint32, len(f))
+	return &status, nil
+}
 
-				for i := range status.CpusAllowed {
-					if n, err = strconv.ParseUint(f[i], 16, 32); err != nil {
-						return nil, err
-					}
-					status.CpusAllowed[i] = uint32(n)
-				}
+// parseStatusIDs parses the real, effective, saved set and filesystem ids of
+// the Uid and Gid lines.
+func parseStatusIDs(v string, ids ...*uint64) error {
 
-			}
-		case "Mems_allowed":
-			{
+	f := strings.Fields(v)
 
-				var n uint64
+	if len(f) != len(ids) {
+		return ErrTooFewFields
+	}
 
-				f := strings.Split(v, ",")
-				status.MemsAllowed = make([]uint32, len(f))
+	var err error
 
-				for i := range status.MemsAllowed {
-					if n, err = strconv.ParseUint(f[i], 16, 32); err != nil {
-						return nil, err
-					}
-					status.MemsAllowed[i] = uint32(n)
-				}
+	for i := range ids {
+		if *ids[i], err = ParseUint(f[i]); err != nil {
+			return err
+		}
+	}
 
-			}
-		case "voluntary_ctxt_switches":
-			if status.VoluntaryCtxtSwitches, err = ParseUint(v); err != nil {
-				return nil, err
-			}
-		case "nonvoluntary_ctxt_switches":
-			if status.NonvoluntaryCtxtSwitches, err = ParseUint(v); err != nil {
-				return nil, err
-			}
+	return nil
+}
+
+// parseStatusUints parses a whitespace separated list of numbers, as in NSpid.
+func parseStatusUints(v string) ([]uint64, error) {
+
+	f := strings.Fields(v)
+	ids := make([]uint64, len(f))
+
+	var err error
+
+	for i := range ids {
+		if ids[i], err = ParseUint(f[i]); err != nil {
+			return nil, err
 		}
 	}
 
-	return &status, nil
+	return ids, nil
+}
+
+// parseStatusBool parses a 0 or 1 flag, as in NoNewPrivs.
+func parseStatusBool(v string) (bool, error) {
+
+	n, err := strconv.ParseUint(v, 10, 8)
+
+	if err != nil {
+		return false, err
+	}
+
+	return n != 0, nil
+}
+
+// parseStatusSignals parses a hex signal mask, as in SigCgt.
+func parseStatusSignals(v string) (SignalSet, error) {
+	n, err := ParseHexUint(v)
+	return SignalSet(n), err
+}
+
+// parseStatusCapabilities parses a hex capability mask, as in CapEff.
+func parseStatusCapabilities(v string) (CapabilitySet, error) {
+	n, err := ParseHexUint(v)
+	return CapabilitySet(n), err
+}
+
+// parseStatusKB parses a "<n> kB" value.
+func parseStatusKB(v string) (uint64, error) {
+
+	f := strings.Fields(v)
+
+	if len(f) == 0 {
+		return 0, ErrTooFewFields
+	}
+
+	return ParseUint(f[0])
+}
+
+// parseStatusMask parses a comma separated list of 32 bit hex words, as in
+// Cpus_allowed.
+func parseStatusMask(v string) ([]uint32, error) {
+
+	f := strings.Split(v, ",")
+	mask := make([]uint32, len(f))
+
+	for i := range mask {
+
+		n, err := strconv.ParseUint(f[i], 16, 32)
+
+		if err != nil {
+			return nil, err
+		}
+
+		mask[i] = uint32(n)
+	}
+
+	return mask, nil
 }
@@ -55,6 +55,7 @@ func TestReadProcessStatus(t *testing.T) {
 		CapBnd:                   18446744073709551615,
 		Seccomp:                  0,
 		CpusAllowed:              []uint32{255},
+		CpusAllowedList:          []int{0, 1, 2, 3, 4, 5, 6, 7},
 		VoluntaryCtxtSwitches:    5899,
 		NonvoluntaryCtxtSwitches: 26,
 	}
@@ -66,3 +67,90 @@ func TestReadProcessStatus(t *testing.T) {
 	t.Logf("%+v", status)
 
 }
+
+func TestReadProcessStatusNewer(t *testing.T) {
+
+	status, err := ReadProcessStatus("proc/4854/task/4854/status")
+
+	if err != nil {
+		t.Fatal("process status read fail", err)
+	}
+
+	if status.Umask != 022 {
+		t.Errorf("unexpected umask %o", status.Umask)
+	}
+
+	if !reflect.DeepEqual(status.NSpid, []uint64{4854, 12}) || !reflect.DeepEqual(status.NSsid, []uint64{4853, 1}) {
+		t.Error("unexpected namespace ids", status.NSpid, status.NSsid)
+	}
+
+	if status.RssAnon != 1384 || status.RssFile != 1776 || status.RssShmem != 0 || status.VmPMD != 12 {
+		t.Error("unexpected memory", status.RssAnon, status.RssFile, status.RssShmem, status.VmPMD)
+	}
+
+	if status.CoreDumping || !status.THPEnabled || !status.NoNewPrivs {
+		t.Error("unexpected flags", status.CoreDumping, status.THPEnabled, status.NoNewPrivs)
+	}
+
+	if status.Seccomp != 2 || status.SeccompFilters != 3 || status.SpeculationStoreBypass != "thread vulnerable" {
+		t.Error("unexpected seccomp", status.Seccomp, status.SeccompFilters, status.SpeculationStoreBypass)
+	}
+
+	if !reflect.DeepEqual(status.CpusAllowedList, []int{0, 1, 2, 3, 8, 9, 10, 11, 12, 13, 14, 15}) {
+		t.Error("unexpected cpus", status.CpusAllowedList)
+	}
+
+	if !reflect.DeepEqual(status.MemsAllowed, []uint32{0, 1}) || !reflect.DeepEqual(status.MemsAllowedList, []int{0}) {
+		t.Error("unexpected mems", status.MemsAllowed, status.MemsAllowedList)
+	}
+
+	extra := map[string]string{
+		"Ngid":                       "0",
+		"Kthread":                    "0",
+		"VmPin":                      "0 kB",
+		"untag_mask":                 "0xffffffffffffffff",
+		"SpeculationIndirectBranch":  "conditional enabled",
+		"x86_Thread_features":        "",
+		"x86_Thread_features_locked": "",
+	}
+
+	if !reflect.DeepEqual(status.Extra, extra) {
+		t.Error("unexpected extra fields", status.Extra)
+	}
+}
+
+func TestParseRangeList(t *testing.T) {
+
+	cases := map[string][]int{
+		"":              {},
+		"0":             {0},
+		"0-3,8-11":      {0, 1, 2, 3, 8, 9, 10, 11},
+		"1,3-4":         {1, 3, 4},
+		"0-7:2/4":       {0, 1, 4, 5},
+		"0-1,4-5:1/2\n": {0, 1, 4},
+	}
+
+	if list, err := ParseRangeList("0-65535"); err != nil || len(list) != 65536 {
+		t.Error("range list parse fail", len(list), err)
+	}
+
+	for s, expected := range cases {
+
+		list, err := ParseRangeList(s)
+
+		if err != nil {
+			t.Error("range list parse fail", s, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(list, expected) {
+			t.Errorf("unexpected list of %q: %v", s, list)
+		}
+	}
+
+	for _, s := range []string{"a", "3-1", "0-3:2", "-1", "0-3:0/2", "0-65536", "0-2147483647"} {
+		if _, err := ParseRangeList(s); err == nil {
+			t.Errorf("expected error parsing %q", s)
+		}
+	}
+}
@@ -56,6 +56,7 @@ func TestReadProcess(t *testing.T) {
 			CapBnd:                   18446744073709551615,
 			Seccomp:                  0,
 			CpusAllowed:              []uint32{255},
+			CpusAllowedList:          []int{0, 1, 2, 3, 4, 5, 6, 7},
 			VoluntaryCtxtSwitches:    5899,
 			NonvoluntaryCtxtSwitches: 26,
 		},
@@ -124,6 +125,7 @@ func TestReadProcess(t *testing.T) {
 			CancelledWriteBytes: 0,
 		},
 		Cmdline: "proftpd: (accepting connections)",
+		Args:    []string{"proftpd: (accepting connections)"},
 	}
 
 	if !reflect.DeepEqual(p, expected) {
@@ -132,3 +134,37 @@ func TestReadProcess(t *testing.T) {
 
 	t.Logf("%+v", p)
 }
+
+func TestReadProcessParts(t *testing.T) {
+
+	p, err := ReadProcessParts(3323, "proc", ProcessStatPart|ProcessCommPart)
+
+	if err != nil {
+		t.Fatal("process read fail", err)
+	}
+
+	if p.Stat.Pid != 3323 || p.Comm != "proftpd" || p.Status.Pid != 0 || p.Cmdline != "" || p.Unavailable != 0 {
+		t.Errorf("unexpected process %+v", p)
+	}
+
+	// Only the stat of 4854 is left, as if it exited while being read.
+	if _, err = ReadProcessParts(4854, "proc", ProcessStatPart); err != nil {
+		t.Error("process read fail", err)
+	}
+
+	if _, err = ReadProcess(4854, "proc"); err != ErrProcessGone {
+		t.Error("unexpected error", err)
+	}
+
+	if _, err = ReadProcess(99999, "proc"); err != ErrProcessGone {
+		t.Error("unexpected error", err)
+	}
+
+	if s := (ProcessIOPart | ProcessStatPart).String(); s != "stat,io" {
+		t.Error("unexpected parts", s)
+	}
+
+	if !AllProcessParts.Has(DefaultProcessParts) || DefaultProcessParts.Has(ProcessCommPart) {
+		t.Error("unexpected parts")
+	}
+}
@@ -0,0 +1,182 @@
+package linuxtool
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Thread is a task of a process, read from /proc/<pid>/task/<tid>.
+type Thread struct {
+	Tid    uint64        `json:"tid"`
+	Comm   string        `json:"comm"`
+	Stat   ProcessStat   `json:"stat"`
+	Status ProcessStatus `json:"status"`
+	IO     ProcessIO     `json:"io"`
+
+	// ProcessIOPart if io could not be read for lack of permission, as for
+	// threads of processes of other users; it is left empty then.
+	Unavailable ProcessParts `json:"unavailable"`
+}
+
+// ThreadUsage is the CPU used by a thread between two samples, in percent of
+// one CPU, as top -H reports it.
+type ThreadUsage struct {
+	Tid    uint64  `json:"tid"`
+	Comm   string  `json:"comm"`
+	User   float64 `json:"user"`
+	System float64 `json:"system"`
+	Total  float64 `json:"total"`
+}
+
+// ListTID returns the thread ids of the process pid, in ascending order, from
+// the proc directory at path.
+func ListTID(pid uint64, path string) ([]uint64, error) {
+	return ListPID(filepath.Join(path, strconv.FormatUint(pid, 10), "task"), ^uint64(0))
+}
+
+// ReadThread reads the stat, status, comm and io of the thread tid of the
+// process pid. ErrProcessGone is returned if the thread does not exist or
+// exits while it is read; io the caller is not permitted to read is flagged in
+// Unavailable.
+func ReadThread(pid uint64, tid uint64, path string) (*Thread, error) {
+	return readThread(pid, tid, path, nil)
+}
+
+func readThread(pid uint64, tid uint64, path string, onError ParseErrorHandler) (*Thread, error) {
+
+	var err error
+
+	p := filepath.Join(path, strconv.FormatUint(pid, 10), "task", strconv.FormatUint(tid, 10))
+
+	var stat *ProcessStat
+	var status *ProcessStatus
+	var io *ProcessIO
+
+	thread := Thread{Tid: tid}
+
+	if stat, err = readProcessStat(filepath.Join(p, "stat"), onError); err != nil {
+		return nil, processError(err)
+	}
+
+	thread.Stat = *stat
+
+	if status, err = readProcessStatus(filepath.Join(p, "status"), onError); err != nil {
+		return nil, processError(err)
+	}
+
+	thread.Status = *status
+
+	if io, err = readProcessIO(filepath.Join(p, "io"), onError); err == nil {
+		thread.IO = *io
+	} else if os.IsPermission(err) {
+		thread.Unavailable |= ProcessIOPart
+	} else {
+		return nil, processError(err)
+	}
+
+	if thread.Comm, err = ReadProcessComm(filepath.Join(p, "comm")); err != nil {
+		return nil, processError(err)
+	}
+
+	return &thread, nil
+}
+
+// ReadThreads reads all threads of the process pid, see ReadThread. Threads
+// exiting while they are read are left out.
+func ReadThreads(pid uint64, path string) ([]Thread, error) {
+	return readThreads(pid, path, nil)
+}
+
+func readThreads(pid uint64, path string, onError ParseErrorHandler) ([]Thread, error) {
+
+	tids, err := ListTID(pid, path)
+
+	if err != nil {
+		return nil, processError(err)
+	}
+
+	threads := make([]Thread, 0, len(tids))
+
+	for _, tid := range tids {
+
+		t, err := readThread(pid, tid, path, onError)
+
+		if err == ErrProcessGone {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		threads = append(threads, *t)
+	}
+
+	return threads, nil
+}
+
+// ReadProcessComm reads the command name of a process or thread from its comm
+// file.
+func ReadProcessComm(path string) (string, error) {
+
+	b, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(string(b), "\n"), nil
+}
+
+// CalculateThreadUsage returns the CPU usage of the threads found in both the
+// prev and curr samples, taken elapsed apart, busiest first. A tid reused by a
+// new thread between the samples is told apart by its start time and left
+// out.
+func CalculateThreadUsage(prev, curr []Thread, elapsed time.Duration) []ThreadUsage {
+
+	type thread struct {
+		tid       uint64
+		starttime uint64
+	}
+
+	before := make(map[thread]*Thread, len(prev))
+
+	for i := range prev {
+		before[thread{prev[i].Tid, prev[i].Stat.Starttime}] = &prev[i]
+	}
+
+	results := make([]ThreadUsage, 0, len(curr))
+
+	seconds := elapsed.Seconds()
+	ticks := float64(ClockTicks())
+
+	for i := range curr {
+
+		c := &curr[i]
+
+		p, ok := before[thread{c.Tid, c.Stat.Starttime}]
+
+		if !ok {
+			continue
+		}
+
+		u := ThreadUsage{Tid: c.Tid, Comm: c.Comm}
+
+		if seconds > 0 {
+			u.User = float64(tickDelta(p.Stat.Utime, c.Stat.Utime)) * 100 / ticks / seconds
+			u.System = float64(tickDelta(p.Stat.Stime, c.Stat.Stime)) * 100 / ticks / seconds
+			u.Total = u.User + u.System
+		}
+
+		results = append(results, u)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Total > results[j].Total })
+
+	return results
+}
@@ -0,0 +1,76 @@
+package linuxtool
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestReadThreads(t *testing.T) {
+
+	tids, err := ListTID(3323, "proc")
+
+	if err != nil {
+		t.Fatal("tid list fail", err)
+	}
+
+	if !reflect.DeepEqual(tids, []uint64{3323, 3324}) {
+		t.Error("unexpected tids", tids)
+	}
+
+	thread, err := ReadThread(3323, 3324, "proc")
+
+	if err != nil {
+		t.Fatal("thread read fail", err)
+	}
+
+	if thread.Tid != 3324 || thread.Comm != "ftp-worker" || thread.Stat.Utime != 412 ||
+		thread.Status.Tgid != 3323 || thread.IO.WriteBytes != 8192 || thread.Unavailable != 0 {
+		t.Errorf("unexpected thread %+v", thread)
+	}
+
+	if _, err = ReadThread(3323, 3325, "proc"); err != ErrProcessGone {
+		t.Error("exited thread should be gone", err)
+	}
+
+	threads, err := NewProcFS("proc").Threads(3323)
+
+	if err != nil {
+		t.Fatal("threads read fail", err)
+	}
+
+	if len(threads) != 2 || threads[0].Comm != "proftpd" || threads[1].Tid != 3324 {
+		t.Errorf("unexpected threads %+v", threads)
+	}
+}
+
+func TestCalculateThreadUsage(t *testing.T) {
+
+	thread := func(tid uint64, start uint64, utime uint64, stime uint64) Thread {
+		return Thread{Tid: tid, Comm: "worker", Stat: ProcessStat{Starttime: start, Utime: utime, Stime: stime}}
+	}
+
+	prev := []Thread{
+		thread(10, 100, 50, 10),
+		thread(11, 100, 0, 0),
+		thread(12, 100, 0, 0),
+	}
+
+	curr := []Thread{
+		thread(10, 100, 100, 20), // 60 ticks in 2s
+		thread(11, 100, 150, 50), // 200 ticks in 2s
+		thread(12, 900, 10, 10),  // tid reused
+		thread(13, 900, 10, 10),  // new thread
+	}
+
+	usages := CalculateThreadUsage(prev, curr, 2*time.Second)
+
+	expected := []ThreadUsage{
+		{Tid: 11, Comm: "worker", User: 75, System: 25, Total: 100},
+		{Tid: 10, Comm: "worker", User: 25, System: 5, Total: 30},
+	}
+
+	if !reflect.DeepEqual(usages, expected) {
+		t.Errorf("unexpected usages %+v", usages)
+	}
+}
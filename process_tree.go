@@ -0,0 +1,328 @@
+package linuxtool
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ProcessNode is a process of a ProcessTree.
+type ProcessNode struct {
+	Pid      uint64         `json:"pid"`
+	PPid     uint64         `json:"ppid"`
+	Process  *Process       `json:"process"`
+	Parent   *ProcessNode   `json:"-"` // nil for roots
+	Children []*ProcessNode `json:"children"`
+}
+
+// ProcessTree links processes to their parent, as pstree does.
+type ProcessTree struct {
+	Nodes map[uint64]*ProcessNode `json:"-"`
+	Roots []*ProcessNode          `json:"roots"` // processes without parent in the tree, by pid
+}
+
+// ProcessTreeUsage is the resource usage of a process and its descendants.
+type ProcessTreeUsage struct {
+	Processes  int    `json:"processes"`
+	RSS        uint64 `json:"rss"`       // kB, the sum of VmRSS, so shared pages are counted once per process
+	CPUTicks   uint64 `json:"cpu_ticks"` // utime + stime, in clock ticks
+	ReadBytes  uint64 `json:"read_bytes"`
+	WriteBytes uint64 `json:"write_bytes"`
+}
+
+// NewProcessTree builds the tree of processes from the parent pid of their
+// stat, or of their status if stat was not read.
+func NewProcessTree(processes []Process) *ProcessTree {
+
+	t := &ProcessTree{Nodes: make(map[uint64]*ProcessNode, len(processes))}
+
+	for i := range processes {
+
+		p := &processes[i]
+
+		n := &ProcessNode{Process: p, Pid: p.Stat.Pid, PPid: uint64(p.Stat.Ppid)}
+
+		if n.Pid == 0 {
+			n.Pid = p.Status.Pid
+			n.PPid = uint64(p.Status.PPid)
+		}
+
+		t.Nodes[n.Pid] = n
+	}
+
+	for _, n := range t.Nodes {
+		if parent, ok := t.Nodes[n.PPid]; ok && n.PPid != n.Pid {
+			n.Parent = parent
+			parent.Children = append(parent.Children, n)
+		} else {
+			t.Roots = append(t.Roots, n)
+		}
+	}
+
+	// A pid reused during the scan can link processes in a cycle, which no
+	// root reaches. Cut it above its lowest pid so that every process is in
+	// the tree once, the same way on every build.
+	reached := make(map[*ProcessNode]bool, len(t.Nodes))
+
+	for _, n := range t.Roots {
+		n.walk(func(d *ProcessNode) { reached[d] = true })
+	}
+
+	pids := make([]uint64, 0, len(t.Nodes))
+
+	for pid := range t.Nodes {
+		pids = append(pids, pid)
+	}
+
+	sort.Slice(pids, func(i, j int) bool { return pids[i] < pids[j] })
+
+	for _, pid := range pids {
+
+		if reached[t.Nodes[pid]] {
+			continue
+		}
+
+		n := processCycleRoot(t.Nodes[pid])
+
+		n.Parent.Children = removeProcessNode(n.Parent.Children, n)
+		n.Parent = nil
+		t.Roots = append(t.Roots, n)
+		n.walk(func(d *ProcessNode) { reached[d] = true })
+	}
+
+	for _, n := range t.Nodes {
+		sortProcessNodes(n.Children)
+	}
+
+	sortProcessNodes(t.Roots)
+
+	return t
+}
+
+// ReadProcessTree reads the processes, without their threads, of the proc
+// directory at path and builds their tree. Processes exiting during the scan
+// are left out; parts it is not permitted to read are left empty, see
+// ReadProcessParts.
+func ReadProcessTree(path string) (*ProcessTree, error) {
+	return readProcessTree(path, nil)
+}
+
+func readProcessTree(path string, onError ParseErrorHandler) (*ProcessTree, error) {
+
+	var processes []Process
+
+	err := WalkTGID(path, func(pid uint64) error {
+
+		p, err := readProcess(pid, path, DefaultProcessParts, onError)
+
+		if err == ErrProcessGone {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		processes = append(processes, *p)
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return NewProcessTree(processes), nil
+}
+
+// Node returns the process pid, or nil if it is not in the tree.
+func (t *ProcessTree) Node(pid uint64) *ProcessNode {
+	return t.Nodes[pid]
+}
+
+// Children returns the direct children of pid, by pid.
+func (t *ProcessTree) Children(pid uint64) []*ProcessNode {
+
+	if n, ok := t.Nodes[pid]; ok {
+		return n.Children
+	}
+
+	return nil
+}
+
+// Descendants returns the children of pid, their children and so on, in depth
+// first order.
+func (t *ProcessTree) Descendants(pid uint64) []*ProcessNode {
+
+	n, ok := t.Nodes[pid]
+
+	if !ok {
+		return nil
+	}
+
+	var descendants []*ProcessNode
+
+	n.walk(func(d *ProcessNode) {
+		if d != n {
+			descendants = append(descendants, d)
+		}
+	})
+
+	return descendants
+}
+
+// Ancestors returns the parent of pid, its parent and so on up to a root.
+func (t *ProcessTree) Ancestors(pid uint64) []*ProcessNode {
+
+	n, ok := t.Nodes[pid]
+
+	if !ok {
+		return nil
+	}
+
+	var ancestors []*ProcessNode
+
+	for p := n.Parent; p != nil; p = p.Parent {
+		ancestors = append(ancestors, p)
+	}
+
+	return ancestors
+}
+
+// Orphans returns the processes whose parent is missing from the tree, that is
+// the roots other than the ones without parent (pid 1 and kthreadd, whose
+// ppid is 0).
+func (t *ProcessTree) Orphans() []*ProcessNode {
+
+	var orphans []*ProcessNode
+
+	for _, n := range t.Roots {
+		if n.PPid != 0 {
+			orphans = append(orphans, n)
+		}
+	}
+
+	return orphans
+}
+
+// Aggregate returns the usage of pid and all its descendants, e.g. to charge a
+// fleet of workers to their supervisor.
+func (t *ProcessTree) Aggregate(pid uint64) ProcessTreeUsage {
+
+	var u ProcessTreeUsage
+
+	n, ok := t.Nodes[pid]
+
+	if !ok {
+		return u
+	}
+
+	n.walk(func(d *ProcessNode) {
+
+		p := d.Process
+
+		u.Processes++
+		u.RSS += p.Status.VmRSS
+		u.CPUTicks += p.Stat.Utime + p.Stat.Stime
+		u.ReadBytes += p.IO.ReadBytes
+		u.WriteBytes += p.IO.WriteBytes
+	})
+
+	return u
+}
+
+// Render writes the tree as pstree -p does, one process per line.
+func (t *ProcessTree) Render(w io.Writer) error {
+
+	var buf bytes.Buffer
+
+	for _, root := range t.Roots {
+		renderProcessNode(&buf, root, "", "", "")
+	}
+
+	_, err := buf.WriteTo(w)
+
+	return err
+}
+
+func (t *ProcessTree) String() string {
+
+	var buf bytes.Buffer
+
+	_ = t.Render(&buf)
+
+	return buf.String()
+}
+
+func renderProcessNode(buf *bytes.Buffer, n *ProcessNode, prefix string, branch string, indent string) {
+
+	buf.WriteString(prefix)
+	buf.WriteString(branch)
+	buf.WriteString(n.Name())
+	buf.WriteString("(")
+	buf.WriteString(strconv.FormatUint(n.Pid, 10))
+	buf.WriteString(")\n")
+
+	for i, c := range n.Children {
+		if i == len(n.Children)-1 {
+			renderProcessNode(buf, c, prefix+indent, "└─", "  ")
+		} else {
+			renderProcessNode(buf, c, prefix+indent, "├─", "│ ")
+		}
+	}
+}
+
+// Name returns the command name of the process.
+func (n *ProcessNode) Name() string {
+
+	if n.Process.Status.Name != "" {
+		return n.Process.Status.Name
+	}
+
+	return strings.TrimSuffix(strings.TrimPrefix(n.Process.Stat.Comm, "("), ")")
+}
+
+// walk calls fn for n and its descendants, in depth first order.
+func (n *ProcessNode) walk(fn func(n *ProcessNode)) {
+	fn(n)
+	for _, c := range n.Children {
+		c.walk(fn)
+	}
+}
+
+// processCycleRoot returns the process of lowest pid of the cycle n leads up
+// to, following its parents.
+func processCycleRoot(n *ProcessNode) *ProcessNode {
+
+	seen := make(map[*ProcessNode]bool)
+
+	for !seen[n] {
+		seen[n] = true
+		n = n.Parent
+	}
+
+	root := n
+
+	for p := n.Parent; p != n; p = p.Parent {
+		if p.Pid < root.Pid {
+			root = p
+		}
+	}
+
+	return root
+}
+
+func removeProcessNode(nodes []*ProcessNode, n *ProcessNode) []*ProcessNode {
+	for i := range nodes {
+		if nodes[i] == n {
+			return append(nodes[:i], nodes[i+1:]...)
+		}
+	}
+	return nodes
+}
+
+func sortProcessNodes(nodes []*ProcessNode) {
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Pid < nodes[j].Pid })
+}
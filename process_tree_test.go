@@ -0,0 +1,116 @@
+package linuxtool
+
+import (
+	"testing"
+)
+
+func treeProcess(pid uint64, ppid int64, name string, rss uint64, ticks uint64, readBytes uint64) Process {
+	return Process{
+		Stat:   ProcessStat{Pid: pid, Ppid: ppid, Comm: "(" + name + ")", Utime: ticks, Stime: ticks},
+		Status: ProcessStatus{Pid: pid, PPid: ppid, Name: name, VmRSS: rss},
+		IO:     ProcessIO{ReadBytes: readBytes, WriteBytes: readBytes / 2},
+	}
+}
+
+func TestProcessTree(t *testing.T) {
+
+	tree := NewProcessTree([]Process{
+		treeProcess(1, 0, "systemd", 10, 1, 0),
+		treeProcess(2, 0, "kthreadd", 0, 0, 0),
+		treeProcess(300, 1, "nginx", 100, 5, 1000),
+		treeProcess(302, 300, "nginx", 200, 10, 2000),
+		treeProcess(301, 300, "nginx", 300, 20, 4000),
+		treeProcess(400, 302, "cgi", 50, 1, 10),
+		treeProcess(500, 499, "orphan", 1, 1, 1),
+		// pid reuse during the scan
+		treeProcess(601, 600, "b", 1, 1, 1),
+		treeProcess(600, 602, "a", 1, 1, 1),
+		treeProcess(602, 601, "c", 1, 1, 1),
+		treeProcess(599, 601, "d", 1, 1, 1),
+	})
+
+	pids := func(nodes []*ProcessNode) []uint64 {
+		var l []uint64
+		for _, n := range nodes {
+			l = append(l, n.Pid)
+		}
+		return l
+	}
+
+	equal := func(a, b []uint64) bool {
+		if len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if a[i] != b[i] {
+				return false
+			}
+		}
+		return true
+	}
+
+	if l := pids(tree.Children(300)); !equal(l, []uint64{301, 302}) {
+		t.Error("unexpected children", l)
+	}
+
+	if l := pids(tree.Descendants(300)); !equal(l, []uint64{301, 302, 400}) {
+		t.Error("unexpected descendants", l)
+	}
+
+	if l := pids(tree.Ancestors(400)); !equal(l, []uint64{302, 300, 1}) {
+		t.Error("unexpected ancestors", l)
+	}
+
+	// The cycle is cut above its lowest pid, the process hanging off it kept.
+	if l := pids(tree.Orphans()); !equal(l, []uint64{500, 600}) {
+		t.Error("unexpected orphans", l)
+	}
+
+	if l := pids(tree.Descendants(600)); !equal(l, []uint64{601, 599, 602}) {
+		t.Error("unexpected descendants", l)
+	}
+
+	if len(tree.Nodes) != 11 || tree.Node(601) == nil || tree.Node(7) != nil {
+		t.Error("unexpected nodes", len(tree.Nodes))
+	}
+
+	u := tree.Aggregate(300)
+
+	expected := ProcessTreeUsage{Processes: 4, RSS: 650, CPUTicks: 72, ReadBytes: 7010, WriteBytes: 3505}
+
+	if u != expected {
+		t.Errorf("unexpected usage %+v", u)
+	}
+
+	rendered := tree.String()
+
+	expectedTree := "systemd(1)\n" +
+		"└─nginx(300)\n" +
+		"  ├─nginx(301)\n" +
+		"  └─nginx(302)\n" +
+		"    └─cgi(400)\n" +
+		"kthreadd(2)\n"
+
+	if len(rendered) < len(expectedTree) || rendered[:len(expectedTree)] != expectedTree {
+		t.Errorf("unexpected tree\n%s", rendered)
+	}
+}
+
+func TestReadProcessTree(t *testing.T) {
+
+	tree, err := ReadProcessTree("proc")
+
+	if err != nil {
+		t.Fatal("process tree read fail", err)
+	}
+
+	n := tree.Node(3323)
+
+	if n == nil || n.Name() != "proftpd" || n.PPid != 1 || n.Process.IO.ReadBytes != 90112 {
+		t.Errorf("unexpected node %+v", n)
+	}
+
+	if l := tree.Orphans(); len(l) != 1 || l[0] != n {
+		t.Error("unexpected orphans", l)
+	}
+}
@@ -0,0 +1,196 @@
+package linuxtool
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ProcessUsage is the activity of a process between two samples, as reported
+// by pidstat and top. CPU percentages are of one CPU, so a process using two
+// CPUs reports 200.
+type ProcessUsage struct {
+	Pid                      uint64  `json:"pid"`
+	Comm                     string  `json:"comm"`
+	User                     float64 `json:"user"`   // %usr
+	System                   float64 `json:"system"` // %system
+	CPU                      float64 `json:"cpu"`    // %CPU
+	Memory                   float64 `json:"memory"` // %MEM, VmRSS of MemTotal
+	RSS                      uint64  `json:"rss"`    // kB
+	ReadBytesPerSec          float64 `json:"read_bytes_per_sec"`
+	WriteBytesPerSec         float64 `json:"write_bytes_per_sec"`
+	MinorFaultsPerSec        float64 `json:"minor_faults_per_sec"`
+	MajorFaultsPerSec        float64 `json:"major_faults_per_sec"`
+	VoluntaryCtxtSwitches    float64 `json:"voluntary_ctxt_switches"`    // cswch/s
+	NonvoluntaryCtxtSwitches float64 `json:"nonvoluntary_ctxt_switches"` // nvcswch/s
+
+	// The io of the process could not be read in one of the samples, see
+	// Process.Unavailable; the I/O rates are 0 then, not measured.
+	IOUnavailable bool `json:"io_unavailable"`
+}
+
+// CalculateProcessUsage returns the activity of a process between its prev and
+// curr samples, taken elapsed apart. memTotal is MemInfo.MemTotal, in kB.
+func CalculateProcessUsage(prev, curr *Process, memTotal uint64, elapsed time.Duration) ProcessUsage {
+
+	u := ProcessUsage{
+		Pid:  curr.Stat.Pid,
+		Comm: strings.TrimSuffix(strings.TrimPrefix(curr.Stat.Comm, "("), ")"),
+		RSS:  curr.Status.VmRSS,
+
+		IOUnavailable: (prev.Unavailable | curr.Unavailable).Has(ProcessIOPart),
+	}
+
+	if memTotal > 0 {
+		u.Memory = float64(curr.Status.VmRSS) * 100 / float64(memTotal)
+	}
+
+	seconds := elapsed.Seconds()
+
+	if seconds <= 0 {
+		return u
+	}
+
+	ticks := float64(ClockTicks())
+
+	rate := func(prev, curr uint64) float64 {
+		return float64(tickDelta(prev, curr)) / seconds
+	}
+
+	u.User = rate(prev.Stat.Utime, curr.Stat.Utime) * 100 / ticks
+	u.System = rate(prev.Stat.Stime, curr.Stat.Stime) * 100 / ticks
+	u.CPU = u.User + u.System
+	if !u.IOUnavailable {
+		u.ReadBytesPerSec = rate(prev.IO.ReadBytes, curr.IO.ReadBytes)
+		u.WriteBytesPerSec = rate(prev.IO.WriteBytes, curr.IO.WriteBytes)
+	}
+	u.MinorFaultsPerSec = rate(prev.Stat.Minflt, curr.Stat.Minflt)
+	u.MajorFaultsPerSec = rate(prev.Stat.Majflt, curr.Stat.Majflt)
+	u.VoluntaryCtxtSwitches = rate(prev.Status.VoluntaryCtxtSwitches, curr.Status.VoluntaryCtxtSwitches)
+	u.NonvoluntaryCtxtSwitches = rate(prev.Status.NonvoluntaryCtxtSwitches, curr.Status.NonvoluntaryCtxtSwitches)
+
+	return u
+}
+
+// ProcessSampler scans the processes of a ProcFS and reports their activity
+// since the previous scan.
+//
+// Processes are matched by pid and start time, so a pid reused by a new
+// process between two scans is not mistaken for the old one.
+type ProcessSampler struct {
+	FS ProcFS
+
+	mu   sync.Mutex
+	prev map[processKey]*Process
+	last time.Time
+}
+
+type processKey struct {
+	pid       uint64
+	starttime uint64
+}
+
+// NewProcessSampler returns a sampler of the processes of fs.
+func NewProcessSampler(fs ProcFS) *ProcessSampler {
+	return &ProcessSampler{FS: fs}
+}
+
+// Sample scans the processes and returns the activity of the ones which were
+// also found by the previous scan, in pid order. The first call only records
+// the scan and returns no usage. Processes whose io cannot be read, because
+// of ptrace restrictions, are flagged IOUnavailable and report no I/O rates,
+// so that they are not mistaken for idle ones.
+func (s *ProcessSampler) Sample() ([]ProcessUsage, error) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	mem, err := s.FS.MemInfo()
+
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	curr := make(map[processKey]*Process)
+
+	err = WalkTGID(s.FS.Root, func(pid uint64) error {
+
+		p, err := readProcess(pid, s.FS.Root, DefaultProcessParts, s.FS.OnParseError)
+
+		if err == ErrProcessGone {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		curr[processKey{p.Stat.Pid, p.Stat.Starttime}] = p
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	var usages []ProcessUsage
+
+	if s.prev != nil {
+
+		elapsed := now.Sub(s.last)
+
+		for k, c := range curr {
+			if p, ok := s.prev[k]; ok {
+				usages = append(usages, CalculateProcessUsage(p, c, mem.MemTotal, elapsed))
+			}
+		}
+
+		sort.Slice(usages, func(i, j int) bool { return usages[i].Pid < usages[j].Pid })
+	}
+
+	s.prev = curr
+	s.last = now
+
+	return usages, nil
+}
+
+// ErrUnknownColumn is returned when sorting by a column ProcessUsage lacks.
+var ErrUnknownColumn = errors.New("unknown column")
+
+// processUsageLess are the orders of SortProcessUsages by column.
+var processUsageLess = map[string]func(a, b *ProcessUsage) bool{
+	"pid":                        func(a, b *ProcessUsage) bool { return a.Pid < b.Pid },
+	"comm":                       func(a, b *ProcessUsage) bool { return a.Comm < b.Comm },
+	"user":                       func(a, b *ProcessUsage) bool { return a.User > b.User },
+	"system":                     func(a, b *ProcessUsage) bool { return a.System > b.System },
+	"cpu":                        func(a, b *ProcessUsage) bool { return a.CPU > b.CPU },
+	"memory":                     func(a, b *ProcessUsage) bool { return a.Memory > b.Memory },
+	"rss":                        func(a, b *ProcessUsage) bool { return a.RSS > b.RSS },
+	"read_bytes_per_sec":         func(a, b *ProcessUsage) bool { return a.ReadBytesPerSec > b.ReadBytesPerSec },
+	"write_bytes_per_sec":        func(a, b *ProcessUsage) bool { return a.WriteBytesPerSec > b.WriteBytesPerSec },
+	"minor_faults_per_sec":       func(a, b *ProcessUsage) bool { return a.MinorFaultsPerSec > b.MinorFaultsPerSec },
+	"major_faults_per_sec":       func(a, b *ProcessUsage) bool { return a.MajorFaultsPerSec > b.MajorFaultsPerSec },
+	"voluntary_ctxt_switches":    func(a, b *ProcessUsage) bool { return a.VoluntaryCtxtSwitches > b.VoluntaryCtxtSwitches },
+	"nonvoluntary_ctxt_switches": func(a, b *ProcessUsage) bool { return a.NonvoluntaryCtxtSwitches > b.NonvoluntaryCtxtSwitches },
+}
+
+// SortProcessUsages sorts usages by column, the json name of a ProcessUsage
+// field, e.g. "cpu" or "write_bytes_per_sec". Numbers are sorted in
+// descending order for top-N reports, pid and comm in ascending order.
+func SortProcessUsages(usages []ProcessUsage, column string) error {
+
+	less, ok := processUsageLess[column]
+
+	if !ok {
+		return ErrUnknownColumn
+	}
+
+	sort.SliceStable(usages, func(i, j int) bool { return less(&usages[i], &usages[j]) })
+
+	return nil
+}
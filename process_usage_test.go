@@ -0,0 +1,119 @@
+package linuxtool
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCalculateProcessUsage(t *testing.T) {
+
+	prev := &Process{
+		Stat:   ProcessStat{Pid: 42, Comm: "(mysqld)", Utime: 1000, Stime: 200, Minflt: 10, Majflt: 1},
+		Status: ProcessStatus{VmRSS: 1024, VoluntaryCtxtSwitches: 100, NonvoluntaryCtxtSwitches: 10},
+		IO:     ProcessIO{ReadBytes: 4096, WriteBytes: 8192},
+	}
+
+	curr := &Process{
+		Stat:   ProcessStat{Pid: 42, Comm: "(mysqld)", Utime: 1300, Stime: 250, Minflt: 110, Majflt: 5},
+		Status: ProcessStatus{VmRSS: 2048, VoluntaryCtxtSwitches: 300, NonvoluntaryCtxtSwitches: 10},
+		IO:     ProcessIO{ReadBytes: 4096 + 40960, WriteBytes: 8192},
+	}
+
+	u := CalculateProcessUsage(prev, curr, 8192, 2*time.Second)
+
+	expected := ProcessUsage{
+		Pid:                   42,
+		Comm:                  "mysqld",
+		User:                  150,
+		System:                25,
+		CPU:                   175,
+		Memory:                25,
+		RSS:                   2048,
+		ReadBytesPerSec:       20480,
+		MinorFaultsPerSec:     50,
+		MajorFaultsPerSec:     2,
+		VoluntaryCtxtSwitches: 100,
+	}
+
+	if u != expected {
+		t.Errorf("unexpected usage %+v", u)
+	}
+
+	// The io of a process of another user.
+	curr.Unavailable = ProcessIOPart
+	curr.IO = ProcessIO{}
+
+	u = CalculateProcessUsage(prev, curr, 8192, 2*time.Second)
+
+	if !u.IOUnavailable || u.ReadBytesPerSec != 0 || u.WriteBytesPerSec != 0 || u.CPU != 175 {
+		t.Errorf("unexpected usage %+v", u)
+	}
+}
+
+func TestSortProcessUsages(t *testing.T) {
+
+	usages := []ProcessUsage{
+		{Pid: 3, Comm: "b", CPU: 10, RSS: 300},
+		{Pid: 1, Comm: "c", CPU: 30, RSS: 100},
+		{Pid: 2, Comm: "a", CPU: 20, RSS: 200},
+	}
+
+	tests := map[string][]uint64{
+		"cpu":  {1, 2, 3},
+		"rss":  {3, 2, 1},
+		"pid":  {1, 2, 3},
+		"comm": {2, 3, 1},
+	}
+
+	for column, pids := range tests {
+
+		if err := SortProcessUsages(usages, column); err != nil {
+			t.Fatal("sort fail", err)
+		}
+
+		for i := range pids {
+			if usages[i].Pid != pids[i] {
+				t.Errorf("unexpected order by %s: %+v", column, usages)
+				break
+			}
+		}
+	}
+
+	// Every column of ProcessUsage but the flags can be sorted by.
+	ty := reflect.TypeOf(ProcessUsage{})
+
+	for i := 0; i < ty.NumField(); i++ {
+		if ty.Field(i).Type.Kind() == reflect.Bool {
+			continue
+		}
+		if err := SortProcessUsages(usages, ty.Field(i).Tag.Get("json")); err != nil {
+			t.Error("sort fail", ty.Field(i).Name, err)
+		}
+	}
+
+	if err := SortProcessUsages(usages, "nope"); err != ErrUnknownColumn {
+		t.Error("unexpected error", err)
+	}
+}
+
+func TestProcessSampler(t *testing.T) {
+
+	s := NewProcessSampler(DefaultProcFS())
+
+	if _, err := s.Sample(); err != nil {
+		t.Fatal("sample fail", err)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+
+	usages, err := s.Sample()
+
+	if err != nil {
+		t.Fatal("sample fail", err)
+	}
+
+	if len(usages) == 0 {
+		t.Error("expect the test process in the usages")
+	}
+}
@@ -0,0 +1,79 @@
+package linuxtool
+
+import (
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// signalNames are the names of the standard signals, by number.
+var signalNames = []string{
+	1: "SIGHUP", 2: "SIGINT", 3: "SIGQUIT", 4: "SIGILL", 5: "SIGTRAP",
+	6: "SIGABRT", 7: "SIGBUS", 8: "SIGFPE", 9: "SIGKILL", 10: "SIGUSR1",
+	11: "SIGSEGV", 12: "SIGUSR2", 13: "SIGPIPE", 14: "SIGALRM", 15: "SIGTERM",
+	16: "SIGSTKFLT", 17: "SIGCHLD", 18: "SIGCONT", 19: "SIGSTOP", 20: "SIGTSTP",
+	21: "SIGTTIN", 22: "SIGTTOU", 23: "SIGURG", 24: "SIGXCPU", 25: "SIGXFSZ",
+	26: "SIGVTALRM", 27: "SIGPROF", 28: "SIGWINCH", 29: "SIGIO", 30: "SIGPWR",
+	31: "SIGSYS",
+}
+
+// sigRTMin is the first real-time signal of the kernel. glibc reserves the
+// first few for threads, so its SIGRTMIN is higher.
+const sigRTMin = 32
+
+// SignalName returns the name of sig, e.g. "SIGTERM", or "SIGRTMIN+<n>" for
+// real-time signals.
+func SignalName(sig syscall.Signal) string {
+
+	switch {
+	case sig > 0 && int(sig) < len(signalNames):
+		return signalNames[sig]
+	case sig == sigRTMin:
+		return "SIGRTMIN"
+	case sig > sigRTMin:
+		return "SIGRTMIN+" + strconv.Itoa(int(sig-sigRTMin))
+	}
+
+	return "SIG" + strconv.Itoa(int(sig))
+}
+
+// SignalSet is a signal bitmask, as in the Sig* lines of /proc/<pid>/status,
+// where bit n-1 stands for signal n.
+type SignalSet uint64
+
+// Has reports whether sig is in the set.
+func (s SignalSet) Has(sig syscall.Signal) bool {
+	return sig > 0 && sig <= 64 && s&(1<<uint(sig-1)) != 0
+}
+
+// Signals returns the signals in the set, in ascending order.
+func (s SignalSet) Signals() []syscall.Signal {
+
+	var sigs []syscall.Signal
+
+	for sig := syscall.Signal(1); sig <= 64; sig++ {
+		if s.Has(sig) {
+			sigs = append(sigs, sig)
+		}
+	}
+
+	return sigs
+}
+
+// List returns the names of the signals in the set, in ascending order.
+func (s SignalSet) List() []string {
+
+	sigs := s.Signals()
+	names := make([]string, len(sigs))
+
+	for i, sig := range sigs {
+		names[i] = SignalName(sig)
+	}
+
+	return names
+}
+
+// String returns the names of the signals in the set, comma separated.
+func (s SignalSet) String() string {
+	return strings.Join(s.List(), ",")
+}
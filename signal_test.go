@@ -0,0 +1,40 @@
+package linuxtool
+
+import (
+	"reflect"
+	"syscall"
+	"testing"
+)
+
+func TestSignalSet(t *testing.T) {
+
+	status, err := ReadProcessStatus("proc/3323/status")
+
+	if err != nil {
+		t.Fatal("process status read fail", err)
+	}
+
+	if !status.SigIgn.Has(syscall.SIGPIPE) || status.SigIgn.Has(syscall.SIGTERM) {
+		t.Error("unexpected ignored signals", status.SigIgn)
+	}
+
+	expected := []string{"SIGPIPE", "SIGURG", "SIGIO"}
+
+	if ignored := status.SigIgn.List(); !reflect.DeepEqual(ignored, expected) {
+		t.Error("unexpected ignored signals", ignored)
+	}
+
+	if status.SigPnd.String() != "" {
+		t.Error("unexpected pending signals", status.SigPnd)
+	}
+
+	s := SignalSet(1<<(syscall.SIGHUP-1) | 1<<(syscall.SIGTERM-1) | 1<<31 | 1<<33 | 1<<63)
+
+	if s.String() != "SIGHUP,SIGTERM,SIGRTMIN,SIGRTMIN+2,SIGRTMIN+32" {
+		t.Error("unexpected string", s.String())
+	}
+
+	if s.Has(0) || s.Has(65) {
+		t.Error("unexpected out of range signal")
+	}
+}
@@ -102,6 +102,10 @@ type Snmp struct {
 }
 
 func ReadSnmp(path string) (*Snmp, error) {
+	return readSnmp(path, nil)
+}
+
+func readSnmp(path string, onError ParseErrorHandler) (*Snmp, error) {
 	data, err := ioutil.ReadFile(path)
 
 	if err != nil {
@@ -110,21 +114,11 @@ func ReadSnmp(path string) (*Snmp, error) {
 
 	lines := strings.Split(string(data), "\n")
 
-	// Maps an SNMP metric to its value (i.e. SyncookiesSent --> 0)
-	statMap := make(map[string]string)
+	// Maps an SNMP metric to its value (e.g. IpForwarding --> 2)
+	statMap, err := parseHeaderValueLines(newParser(path, onError), lines, true)
 
-	// patterns
-	// Ip: Forwarding DefaultTTL InReceives InHdrErrors... <-- header
-	// Ip: 2 64 9305753793 0 0 0 0 0... <-- values
-
-	for i := 1; i < len(lines); i = i + 2 {
-		headers := strings.Fields(lines[i-1][strings.Index(lines[i-1], ":")+1:])
-		values := strings.Fields(lines[i][strings.Index(lines[i], ":")+1:])
-		protocol := strings.Replace(strings.Fields(lines[i-1])[0], ":", "", -1)
-
-		for j, header := range headers {
-			statMap[protocol+header] = values[j]
-		}
+	if err != nil {
+		return nil, err
 	}
 
 	var snmp Snmp = Snmp{}
@@ -134,10 +128,65 @@ func ReadSnmp(path string) (*Snmp, error) {
 
 	for i := 0; i < elem.NumField(); i++ {
 		if val, ok := statMap[typeOfElem.Field(i).Name]; ok {
-			parsedVal, _ := ParseUint(val)
-			elem.Field(i).SetUint(parsedVal)
+			elem.Field(i).SetUint(val)
 		}
 	}
 
 	return &snmp, nil
 }
+
+// parseHeaderValueLines parses the pairs of header and value lines shared by
+// /proc/net/snmp and /proc/net/netstat:
+//
+// Ip: Forwarding DefaultTTL InReceives InHdrErrors... <-- header
+// Ip: 2 64 9305753793 0 0 0 0 0... <-- values
+//
+// The keys of the returned map are the header names, prefixed by the
+// protocol (e.g. IpForwarding) if withProtocol is set.
+func parseHeaderValueLines(p *parser, lines []string, withProtocol bool) (map[string]uint64, error) {
+
+	statMap := make(map[string]uint64)
+
+	for i := 1; i < len(lines); i = i + 2 {
+		headerFields := strings.Fields(lines[i-1])
+		valueFields := strings.Fields(lines[i])
+
+		if len(headerFields) == 0 && len(valueFields) == 0 {
+			continue
+		}
+
+		if len(headerFields) == 0 || len(valueFields) != len(headerFields) {
+			if err := p.fail(i+1, "", lines[i], ErrTooFewFields); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		protocol := strings.TrimSuffix(headerFields[0], ":")
+
+		for j, header := range headerFields[1:] {
+			if withProtocol {
+				header = protocol + header
+			}
+
+			val, err := ParseUint(valueFields[j+1])
+
+			// Signed counters such as Tcp MaxConn use -1 for "no limit",
+			// which is read as 0.
+			if _, ierr := ParseInt(valueFields[j+1]); err != nil && ierr == nil {
+				val, err = 0, nil
+			}
+
+			if err != nil {
+				if err = p.fail(i+1, header, lines[i], err); err != nil {
+					return nil, err
+				}
+				continue
+			}
+
+			statMap[header] = val
+		}
+	}
+
+	return statMap, nil
+}
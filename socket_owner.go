@@ -0,0 +1,167 @@
+package linuxtool
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+// SocketOwner is a process holding a socket open, as netstat -p and ss -p
+// report it.
+type SocketOwner struct {
+	Pid  uint64 `json:"pid"`
+	Comm string `json:"comm"`
+	Uid  uint32 `json:"uid"` // effective uid of the process
+	FD   uint64 `json:"fd"`
+
+	// The status of the process could not be read; Uid is 0 then, not root.
+	UidUnknown bool `json:"uid_unknown"`
+}
+
+// SocketResolver maps socket inodes to the processes holding them, from one
+// scan of the fds of all processes.
+type SocketResolver struct {
+	Owners map[uint64][]SocketOwner // by socket inode, in pid and fd order
+
+	// Pids whose fd directory or fd links could not be read for lack of
+	// permission; sockets they hold are left unresolved. Reading the fds of
+	// processes of other users requires root or CAP_SYS_PTRACE.
+	Denied []uint64
+}
+
+// NewSocketResolver scans the fds of the processes of the proc directory at
+// path. Processes exiting during the scan are skipped.
+func NewSocketResolver(path string) (*SocketResolver, error) {
+	return newSocketResolver(path, nil, os.Readlink)
+}
+
+func newSocketResolver(path string, onError ParseErrorHandler, readlink func(string) (string, error)) (*SocketResolver, error) {
+
+	r := &SocketResolver{Owners: make(map[uint64][]SocketOwner)}
+
+	err := WalkPID(path, func(pid uint64) error {
+
+		dir := filepath.Join(path, strconv.FormatUint(pid, 10))
+
+		var owner *SocketOwner
+
+		// Sockets of the process, added to Owners once all its fds are read.
+		found := make(map[uint64][]SocketOwner)
+
+		err := walkProcessFDs(dir, readlink, func(fd uint64, target string) error {
+
+			t, inode, _ := parseFDTarget(target)
+
+			if t != FDTypeSocket {
+				return nil
+			}
+
+			// The comm and uid are read once, for processes holding sockets.
+			if owner == nil {
+
+				owner = &SocketOwner{Pid: pid}
+
+				owner.Comm, _ = ReadProcessComm(filepath.Join(dir, "comm"))
+
+				if status, err := readProcessStatus(filepath.Join(dir, "status"), onError); err == nil {
+					owner.Uid = uint32(status.EffectiveUid)
+				} else {
+					owner.UidUnknown = true
+				}
+			}
+
+			o := *owner
+			o.FD = fd
+
+			found[inode] = append(found[inode], o)
+
+			return nil
+		})
+
+		if os.IsPermission(err) {
+			r.Denied = append(r.Denied, pid)
+			return nil
+		}
+
+		if err != nil {
+			if processError(err) == ErrProcessGone {
+				return nil
+			}
+			return err
+		}
+
+		for inode, owners := range found {
+			r.Owners[inode] = append(r.Owners[inode], owners...)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, owners := range r.Owners {
+		sort.Slice(owners, func(i, j int) bool {
+			if owners[i].Pid != owners[j].Pid {
+				return owners[i].Pid < owners[j].Pid
+			}
+			return owners[i].FD < owners[j].FD
+		})
+	}
+
+	sort.Slice(r.Denied, func(i, j int) bool { return r.Denied[i] < r.Denied[j] })
+
+	return r, nil
+}
+
+// Lookup returns the processes holding the socket inode, several if it was
+// inherited across fork.
+func (r *SocketResolver) Lookup(inode uint64) []SocketOwner {
+	return r.Owners[inode]
+}
+
+// annotate sets the owners of socket and reports whether it was resolved.
+// Sockets without an inode, e.g. in TIME_WAIT, have no owner to resolve.
+func (r *SocketResolver) annotate(socket *NetSocket) bool {
+
+	if socket.Inode == 0 {
+		return true
+	}
+
+	socket.Owners = r.Lookup(socket.Inode)
+
+	return len(socket.Owners) > 0
+}
+
+// AnnotateTCP sets the Owners of the sockets and returns the inodes of the
+// ones no process was found for, because they are held by Denied processes,
+// by the kernel, or were opened after the scan.
+func (r *SocketResolver) AnnotateTCP(sockets *NetTCPSockets) []uint64 {
+
+	var unresolved []uint64
+
+	for i := range sockets.Sockets {
+		if !r.annotate(&sockets.Sockets[i].NetSocket) {
+			unresolved = append(unresolved, sockets.Sockets[i].Inode)
+		}
+	}
+
+	return unresolved
+}
+
+// AnnotateUDP sets the Owners of the sockets and returns the inodes of the
+// ones no process was found for, see AnnotateTCP.
+func (r *SocketResolver) AnnotateUDP(sockets *NetUDPSockets) []uint64 {
+
+	var unresolved []uint64
+
+	for i := range sockets.Sockets {
+		if !r.annotate(&sockets.Sockets[i].NetSocket) {
+			unresolved = append(unresolved, sockets.Sockets[i].Inode)
+		}
+	}
+
+	return unresolved
+}
@@ -0,0 +1,119 @@
+package linuxtool
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestSocketResolver(t *testing.T) {
+
+	r, err := NewProcFS("proc").SocketResolver()
+
+	if err != nil {
+		t.Fatal("socket resolver fail", err)
+	}
+
+	expected := map[uint64][]SocketOwner{
+		4635: {{Pid: 3323, Comm: "proftpd", Uid: 111, FD: 2}},
+	}
+
+	if !reflect.DeepEqual(r.Owners, expected) || len(r.Denied) != 0 {
+		t.Errorf("unexpected resolver %+v", r)
+	}
+
+	tcp, err := ReadNetTCPSockets("proc/3323/net/tcp", NetIPv4Decoder)
+
+	if err != nil {
+		t.Fatal("tcp sockets read fail", err)
+	}
+
+	unresolved := r.AnnotateTCP(tcp)
+
+	if len(unresolved) != 25 {
+		t.Error("unexpected unresolved sockets", unresolved)
+	}
+
+	found := false
+
+	for _, s := range tcp.Sockets {
+
+		if s.Inode != 4635 {
+			if s.Owners != nil {
+				t.Error("unexpected owners of", s.Inode, s.Owners)
+			}
+			continue
+		}
+
+		found = true
+
+		if s.LocalAddress != "0.0.0.0:21" || !reflect.DeepEqual(s.Owners, expected[4635]) {
+			t.Errorf("unexpected ftp socket %+v", s)
+		}
+	}
+
+	if !found {
+		t.Error("ftp socket not found")
+	}
+
+	udp, err := ReadNetUDPSockets("proc/3323/net/udp", NetIPv4Decoder)
+
+	if err != nil {
+		t.Fatal("udp sockets read fail", err)
+	}
+
+	if unresolved = r.AnnotateUDP(udp); len(unresolved) != len(udp.Sockets) {
+		t.Error("unexpected resolved udp sockets", len(unresolved), len(udp.Sockets))
+	}
+}
+
+func TestSocketResolverDenied(t *testing.T) {
+
+	// The fd links of a process of another user.
+	readlink := func(name string) (string, error) {
+		if strings.HasPrefix(name, filepath.Join("proc", "3323")) {
+			return "", &os.PathError{Op: "readlink", Path: name, Err: syscall.EACCES}
+		}
+		return os.Readlink(name)
+	}
+
+	r, err := newSocketResolver("proc", nil, readlink)
+
+	if err != nil {
+		t.Fatal("socket resolver fail", err)
+	}
+
+	if !reflect.DeepEqual(r.Denied, []uint64{3323}) || len(r.Owners) != 0 {
+		t.Errorf("unexpected resolver %+v", r)
+	}
+}
+
+func TestSocketResolverUidUnknown(t *testing.T) {
+
+	// A socket of a process whose status cannot be read.
+	readlink := func(name string) (string, error) {
+		if name == filepath.Join("proc", "4854", "fd", "6") {
+			return "socket:[9999]", nil
+		}
+		return os.Readlink(name)
+	}
+
+	r, err := newSocketResolver("proc", nil, readlink)
+
+	if err != nil {
+		t.Fatal("socket resolver fail", err)
+	}
+
+	expected := []SocketOwner{{Pid: 4854, Comm: "(sd-pam)", FD: 6, UidUnknown: true}}
+
+	if owners := r.Lookup(9999); !reflect.DeepEqual(owners, expected) {
+		t.Errorf("unexpected owners %+v", owners)
+	}
+
+	if owners := r.Lookup(4635); len(owners) != 1 || owners[0].UidUnknown {
+		t.Errorf("unexpected owners %+v", owners)
+	}
+}
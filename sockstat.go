@@ -49,12 +49,18 @@ type SockStat struct {
 }
 
 func ReadSockStat(path string) (*SockStat, error) {
+	return readSockStat(path, nil)
+}
+
+func readSockStat(path string, onError ParseErrorHandler) (*SockStat, error) {
 	data, err := ioutil.ReadFile(path)
 
 	if err != nil {
 		return nil, err
 	}
 
+	p := newParser(path, onError)
+
 	lines := strings.Split(string(data), "\n")
 
 	// Maps a meminfo metric to its value (i.e. MemTotal --> 100000)
@@ -62,7 +68,7 @@ func ReadSockStat(path string) (*SockStat, error) {
 
 	var sockStat SockStat = SockStat{}
 
-	for _, line := range lines {
+	for i, line := range lines {
 		if !strings.Contains(line, ":") {
 			continue
 		}
@@ -71,14 +77,24 @@ func ReadSockStat(path string) (*SockStat, error) {
 
 		// The fields have this pattern: inuse 27 orphan 1 tw 23 alloc 31 mem 3
 		// The stats are grouped into pairs and need to be parsed and placed into the stat map.
-		key := ""
-		for k, v := range strings.Fields(line[strings.Index(line, ":")+1:]) {
-			// Every second field is a value.
-			if (k+1)%2 != 0 {
-				key = v
+		fields := strings.Fields(line[strings.Index(line, ":")+1:])
+
+		if len(fields)%2 != 0 {
+			if err = p.fail(i+1, "", line, ErrTooFewFields); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		for k := 0; k < len(fields); k += 2 {
+			val, err := ParseUint(fields[k+1])
+			if err != nil {
+				if err = p.fail(i+1, statType+fields[k], line, err); err != nil {
+					return nil, err
+				}
 				continue
 			}
-			statMap[statType+key] = ParseUint64(v)
+			statMap[statType+fields[k]] = val
 		}
 	}
 
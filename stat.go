@@ -31,12 +31,20 @@ type CPUStat struct {
 	GuestNice uint64 `json:"guest_nice"`
 }
 
-func createCPUStat(fields []string) *CPUStat {
+var cpuStatFields = []string{
+	"id", "user", "nice", "system", "idle", "iowait",
+	"irq", "softirq", "steal", "guest", "guest_nice",
+}
+
+func createCPUStat(fields []string) (*CPUStat, error) {
 	s := CPUStat{}
 	s.Id = fields[0]
 
-	for i := 1; i < len(fields); i++ {
-		v, _ := ParseUint(fields[i])
+	for i := 1; i < len(fields) && i < len(cpuStatFields); i++ {
+		v, err := ParseUint(fields[i])
+		if err != nil {
+			return nil, &fieldError{cpuStatFields[i], err}
+		}
 		switch i {
 		case 1:
 			s.User = v
@@ -60,14 +68,19 @@ func createCPUStat(fields []string) *CPUStat {
 			s.GuestNice = v
 		}
 	}
-	return &s
+	return &s, nil
 }
 
 func ReadStat(path string) (*Stat, error) {
+	return readStat(path, nil)
+}
+
+func readStat(path string, onError ParseErrorHandler) (*Stat, error) {
 	b, err := ioutil.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
+	p := newParser(path, onError)
 	content := string(b)
 	lines := strings.Split(content, "\n")
 
@@ -78,27 +91,54 @@ func ReadStat(path string) (*Stat, error) {
 		if len(fields) == 0 {
 			continue
 		}
-		if fields[0][:3] == "cpu" {
-			if cpuStat := createCPUStat(fields); cpuStat != nil {
-				if i == 0 {
-					stat.CPUStatAll = *cpuStat
-				} else {
-					stat.CPUStats = append(stat.CPUStats, *cpuStat)
+		if strings.HasPrefix(fields[0], "cpu") {
+			cpuStat, err := createCPUStat(fields)
+			if err != nil {
+				if err = p.fail(i+1, "", line, err); err != nil {
+					return nil, err
 				}
+				continue
+			}
+			if i == 0 {
+				stat.CPUStatAll = *cpuStat
+			} else {
+				stat.CPUStats = append(stat.CPUStats, *cpuStat)
+			}
+			continue
+		}
+
+		var target *uint64
+		switch fields[0] {
+		case "intr":
+			target = &stat.Interrupts
+		case "ctxt":
+			target = &stat.ContextSwitches
+		case "processes":
+			target = &stat.Processes
+		case "procs_running":
+			target = &stat.ProcsRunning
+		case "procs_blocked":
+			target = &stat.ProcsBlocked
+		case "btime":
+		default:
+			continue
+		}
+
+		if len(fields) < 2 {
+			err = ErrTooFewFields
+		} else if target != nil {
+			*target, err = ParseUint(fields[1])
+		} else {
+			var seconds int64
+			if seconds, err = ParseInt(fields[1]); err == nil {
+				stat.BootTime = time.Unix(seconds, 0)
+			}
+		}
+
+		if err != nil {
+			if err = p.fail(i+1, fields[0], line, err); err != nil {
+				return nil, err
 			}
-		} else if fields[0] == "intr" {
-			stat.Interrupts = ParseUint64(fields[1])
-		} else if fields[0] == "ctxt" {
-			stat.ContextSwitches = ParseUint64(fields[1])
-		} else if fields[0] == "btime" {
-			seconds, _ := ParseInt(fields[1])
-			stat.BootTime = time.Unix(seconds, 0)
-		} else if fields[0] == "processes" {
-			stat.Processes = ParseUint64(fields[1])
-		} else if fields[0] == "procs_running" {
-			stat.ProcsRunning = ParseUint64(fields[1])
-		} else if fields[0] == "procs_blocked" {
-			stat.ProcsBlocked = ParseUint64(fields[1])
 		}
 	}
 	return &stat, nil
@@ -26,17 +26,33 @@ func (uptime *Uptime) CalculateIdle() float64 {
 }
 
 func ReadUptime(path string) (*Uptime, error) {
+	return readUptime(path, nil)
+}
+
+func readUptime(path string, onError ParseErrorHandler) (*Uptime, error) {
 	b, err := ioutil.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
-	fields := strings.Fields(string(b))
+	p := newParser(path, onError)
+	content := strings.TrimSpace(string(b))
+	fields := strings.Fields(content)
 	uptime := Uptime{}
+	if len(fields) < 2 {
+		if err = p.fail(1, "", content, ErrTooFewFields); err != nil {
+			return nil, err
+		}
+		return &uptime, nil
+	}
 	if uptime.Total, err = ParseFloat(fields[0]); err != nil {
-		return nil, err
+		if err = p.fail(1, "total", content, err); err != nil {
+			return nil, err
+		}
 	}
 	if uptime.Idle, err = ParseFloat(fields[1]); err != nil {
-		return nil, err
+		if err = p.fail(1, "idle", content, err); err != nil {
+			return nil, err
+		}
 	}
 	return &uptime, nil
 }
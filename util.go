@@ -1,6 +1,9 @@
 package linuxtool
 
-import "strconv"
+import (
+	"strconv"
+	"strings"
+)
 
 func ParseInt(s string) (int64, error) {
 	return strconv.ParseInt(s, 10, 64)
@@ -53,3 +56,81 @@ func ParseFloat64(s string) float64 {
 	f64, _ := strconv.ParseFloat(s, 64)
 	return f64
 }
+
+// maxRangeWidth bounds the numbers a single range of a range list may span.
+const maxRangeWidth = 1 << 16
+
+// ParseRangeList expands a list of ranges in the kernel's list format, as in
+// Cpus_allowed_list or /sys/devices/system/cpu/online, e.g. "0-3,8-11" or
+// "0-7:2/4" for every 4th group of 2, to the numbers it contains in order.
+// Ranges wider than maxRangeWidth, far more than any cpu or node count, are
+// rejected rather than expanded.
+func ParseRangeList(s string) ([]int, error) {
+
+	s = strings.TrimSpace(s)
+
+	list := []int{}
+
+	if s == "" {
+		return list, nil
+	}
+
+	for _, r := range strings.Split(s, ",") {
+
+		// Optional group size and stride of the range.
+		used, group := 1, 1
+
+		if i := strings.IndexByte(r, ':'); i >= 0 {
+
+			g := strings.SplitN(r[i+1:], "/", 2)
+
+			if len(g) != 2 {
+				return nil, ErrTooFewFields
+			}
+
+			var err error
+
+			if used, err = strconv.Atoi(g[0]); err != nil {
+				return nil, err
+			}
+
+			if group, err = strconv.Atoi(g[1]); err != nil {
+				return nil, err
+			}
+
+			if used <= 0 || group <= 0 {
+				return nil, strconv.ErrRange
+			}
+
+			r = r[:i]
+		}
+
+		bounds := strings.SplitN(r, "-", 2)
+
+		start, err := strconv.Atoi(bounds[0])
+
+		if err != nil {
+			return nil, err
+		}
+
+		end := start
+
+		if len(bounds) == 2 {
+			if end, err = strconv.Atoi(bounds[1]); err != nil {
+				return nil, err
+			}
+		}
+
+		if start < 0 || end < start || end-start >= maxRangeWidth {
+			return nil, strconv.ErrRange
+		}
+
+		for n := start; n <= end; n++ {
+			if (n-start)%group < used {
+				list = append(list, n)
+			}
+		}
+	}
+
+	return list, nil
+}
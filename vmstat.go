@@ -123,20 +123,37 @@ type VMStat struct {
 }
 
 func ReadVMStat(path string) (*VMStat, error) {
+	return readVMStat(path, nil)
+}
+
+func readVMStat(path string, onError ParseErrorHandler) (*VMStat, error) {
 	b, err := ioutil.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
+	p := newParser(path, onError)
 	content := string(b)
 	lines := strings.Split(content, "\n")
 	vmstat := VMStat{}
-	for _, line := range lines {
+	for i, line := range lines {
 		fields := strings.Fields(line)
-		if len(fields) != 2 {
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 2 {
+			if err = p.fail(i+1, "", line, ErrTooFewFields); err != nil {
+				return nil, err
+			}
 			continue
 		}
 		name := fields[0]
-		value := ParseUint64(fields[1])
+		value, err := ParseUint(fields[1])
+		if err != nil {
+			if err = p.fail(i+1, name, line, err); err != nil {
+				return nil, err
+			}
+			continue
+		}
 		switch name {
 		case "nr_free_pages":
 			vmstat.NrFreePages = value